@@ -0,0 +1,34 @@
+// Package analytics derives events and statistics, such as line crossings, from tracked detections.
+package analytics
+
+import (
+	"image"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+// Anchor determines which point of a bounding box represents the position of an object.
+type Anchor int
+
+// Supported anchors.
+const (
+	// BottomCenter is the center of the bottom edge of the box, where a person or vehicle touches the ground
+	BottomCenter Anchor = iota
+	// Center is the center of the box
+	Center
+)
+
+// Point returns the anchor point of given box.
+func (a Anchor) Point(box image.Rectangle) image.Point {
+	switch a {
+	case Center:
+		return image.Pt((box.Min.X+box.Max.X)/2, (box.Min.Y+box.Max.Y)/2)
+	default:
+		return image.Pt((box.Min.X+box.Max.X)/2, box.Max.Y)
+	}
+}
+
+// matchesClass reports whether the class of the track is part of given classes, all classes match an empty set.
+func matchesClass(classes map[string]bool, t track.Track) bool {
+	return len(classes) == 0 || classes[t.Detection.ClassName]
+}
@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+// Direction of a line crossing.
+type Direction int
+
+// Possible directions of a line crossing. Looking from A to B on screen, In is a crossing from
+// the left to the right hand side of the line. For a horizontal line from left to right, In is a
+// crossing from top to bottom.
+const (
+	In Direction = iota
+	Out
+)
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	if d == In {
+		return "in"
+	}
+	return "out"
+}
+
+// Line a virtual line segment from A to B.
+type Line struct {
+	A, B image.Point
+}
+
+// signedDistance returns the distance of given point to the infinite line through A and B,
+// positive on the right hand side. The second value is the position of the projection of the
+// point on the segment, where 0 is A and 1 is B.
+func (l Line) signedDistance(p image.Point) (float64, float64) {
+	dx, dy := float64(l.B.X-l.A.X), float64(l.B.Y-l.A.Y)
+	px, py := float64(p.X-l.A.X), float64(p.Y-l.A.Y)
+	length := math.Hypot(dx, dy)
+	return (dx*py - dy*px) / length, (dx*px + dy*py) / (length * length)
+}
+
+// LineCounterConfig can be used to configure a line counter.
+type LineCounterConfig struct {
+	Line Line
+	// Classes contains the class names which are counted, all classes are counted when empty
+	Classes map[string]bool
+	// Hysteresis is the distance in pixels an object should move away from the line before it is
+	// considered to be on the other side, so jitter along the line is not counted
+	Hysteresis float64
+	// Anchor is the point of the bounding box which should cross the line
+	Anchor Anchor
+}
+
+// Crossing a track which crossed the line.
+type Crossing struct {
+	TrackID   int
+	ClassName string
+	Direction Direction
+	Timestamp time.Time
+	// Point is the anchor point of the track after crossing
+	Point image.Point
+}
+
+// Counts the amount of crossings in every direction.
+type Counts struct {
+	In  int
+	Out int
+}
+
+// LineCounter counts the tracks crossing a line. The counter is not safe for concurrent use.
+type LineCounter struct {
+	config  LineCounterConfig
+	sides   map[int]int
+	counts  Counts
+	classes map[string]Counts
+}
+
+// NewLineCounter creates a line counter with given config.
+func NewLineCounter(config LineCounterConfig) (*LineCounter, error) {
+	if config.Line.A == config.Line.B {
+		return nil, fmt.Errorf("line should have two distinct end points, got %v", config.Line.A)
+	}
+	if config.Hysteresis < 0 {
+		return nil, fmt.Errorf("hysteresis should not be negative, got %f", config.Hysteresis)
+	}
+	return &LineCounter{
+		config:  config,
+		sides:   map[int]int{},
+		classes: map[string]Counts{},
+	}, nil
+}
+
+// Update processes the tracks of the next frame and returns the crossings which occurred.
+// Only confirmed tracks can cross the line, tracks which are no longer present are forgotten.
+func (c *LineCounter) Update(tracks []track.Track, timestamp time.Time) []Crossing {
+	crossings := []Crossing{}
+	present := make(map[int]bool, len(tracks))
+	for _, t := range tracks {
+		present[t.ID] = true
+		if t.State != track.Confirmed || !matchesClass(c.config.Classes, t) {
+			continue
+		}
+
+		point := c.config.Anchor.Point(t.BoundingBox)
+		distance, position := c.config.Line.signedDistance(point)
+		side := 0
+		switch {
+		case distance > c.config.Hysteresis:
+			side = 1
+		case distance < -c.config.Hysteresis:
+			side = -1
+		}
+		previous, known := c.sides[t.ID]
+		if side == 0 {
+			continue
+		}
+		c.sides[t.ID] = side
+		if !known || previous == side || position < 0 || position > 1 {
+			continue
+		}
+
+		crossing := Crossing{
+			TrackID:   t.ID,
+			ClassName: t.Detection.ClassName,
+			Direction: Out,
+			Timestamp: timestamp,
+			Point:     point,
+		}
+		if side > 0 {
+			crossing.Direction = In
+		}
+		c.count(crossing)
+		crossings = append(crossings, crossing)
+	}
+
+	for id := range c.sides {
+		if !present[id] {
+			delete(c.sides, id)
+		}
+	}
+	return crossings
+}
+
+// Counts returns the total amount of crossings.
+func (c *LineCounter) Counts() Counts {
+	return c.counts
+}
+
+// CountsByClass returns the amount of crossings of every class.
+func (c *LineCounter) CountsByClass() map[string]Counts {
+	counts := make(map[string]Counts, len(c.classes))
+	for class, count := range c.classes {
+		counts[class] = count
+	}
+	return counts
+}
+
+// count adds the crossing to the counts.
+func (c *LineCounter) count(crossing Crossing) {
+	class := c.classes[crossing.ClassName]
+	if crossing.Direction == In {
+		c.counts.In++
+		class.In++
+	} else {
+		c.counts.Out++
+		class.Out++
+	}
+	c.classes[crossing.ClassName] = class
+}
@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/detect"
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+type LineTestSuite struct {
+	suite.Suite
+	start time.Time
+}
+
+func TestLineTestSuite(t *testing.T) {
+	suite.Run(t, new(LineTestSuite))
+}
+
+func (s *LineTestSuite) SetupTest() {
+	s.start = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+}
+
+// confirmed creates a confirmed track of given class, of which the bottom center is at given point.
+func confirmed(id int, className string, x, y int) track.Track {
+	return track.Track{
+		ID:          id,
+		State:       track.Confirmed,
+		Detection:   detect.ObjectDetection{ClassName: className},
+		BoundingBox: image.Rect(x-10, y-40, x+10, y),
+	}
+}
+
+// run feeds the y positions of a single track to the counter, one per frame.
+func (s *LineTestSuite) run(counter *LineCounter, className string, x int, ys ...int) []Crossing {
+	crossings := []Crossing{}
+	for i, y := range ys {
+		timestamp := s.start.Add(time.Duration(i) * time.Second)
+		crossings = append(crossings, counter.Update([]track.Track{confirmed(1, className, x, y)}, timestamp)...)
+	}
+	return crossings
+}
+
+func (s *LineTestSuite) TestNewLineCounterInvalidConfig() {
+	_, err := NewLineCounter(LineCounterConfig{Line: Line{A: image.Pt(1, 1), B: image.Pt(1, 1)}})
+	s.Error(err)
+	_, err = NewLineCounter(LineCounterConfig{Line: Line{A: image.Pt(0, 0), B: image.Pt(1, 1)}, Hysteresis: -1})
+	s.Error(err)
+}
+
+func (s *LineTestSuite) TestDirectionalCrossings() {
+	counter, err := NewLineCounter(LineCounterConfig{
+		Line:       Line{A: image.Pt(0, 100), B: image.Pt(200, 100)},
+		Hysteresis: 5,
+	})
+	s.Require().NoError(err)
+
+	crossings := s.run(counter, "person", 100, 50, 80, 120, 150, 120, 80)
+	s.Equal([]Crossing{
+		{TrackID: 1, ClassName: "person", Direction: In, Timestamp: s.start.Add(2 * time.Second), Point: image.Pt(100, 120)},
+		{TrackID: 1, ClassName: "person", Direction: Out, Timestamp: s.start.Add(5 * time.Second), Point: image.Pt(100, 80)},
+	}, crossings)
+	s.Equal(Counts{In: 1, Out: 1}, counter.Counts())
+	s.Equal(map[string]Counts{"person": {In: 1, Out: 1}}, counter.CountsByClass())
+}
+
+func (s *LineTestSuite) TestHysteresis() {
+	counter, err := NewLineCounter(LineCounterConfig{
+		Line:       Line{A: image.Pt(0, 100), B: image.Pt(200, 100)},
+		Hysteresis: 5,
+	})
+	s.Require().NoError(err)
+
+	// Jitter along the line is not counted, the final crossing is counted once
+	crossings := s.run(counter, "person", 100, 80, 97, 103, 98, 104, 99, 110)
+	s.Require().Len(crossings, 1)
+	s.Equal(In, crossings[0].Direction)
+	s.Equal(Counts{In: 1}, counter.Counts())
+
+	// Without hysteresis every jitter is counted
+	counter, err = NewLineCounter(LineCounterConfig{Line: Line{A: image.Pt(0, 100), B: image.Pt(200, 100)}})
+	s.Require().NoError(err)
+	s.Len(s.run(counter, "person", 100, 80, 97, 103, 98, 104, 99, 110), 5)
+}
+
+func (s *LineTestSuite) TestOutsideSegment() {
+	counter, err := NewLineCounter(LineCounterConfig{Line: Line{A: image.Pt(0, 100), B: image.Pt(200, 100)}})
+	s.Require().NoError(err)
+	s.Empty(s.run(counter, "person", 300, 50, 150))
+}
+
+func (s *LineTestSuite) TestClasses() {
+	counter, err := NewLineCounter(LineCounterConfig{
+		Line:    Line{A: image.Pt(0, 100), B: image.Pt(200, 100)},
+		Classes: map[string]bool{"person": true},
+	})
+	s.Require().NoError(err)
+	s.Empty(s.run(counter, "car", 100, 50, 150))
+	s.Len(s.run(counter, "person", 100, 50, 150), 1)
+}
+
+func (s *LineTestSuite) TestUnconfirmedAndForgottenTracks() {
+	counter, err := NewLineCounter(LineCounterConfig{Line: Line{A: image.Pt(0, 100), B: image.Pt(200, 100)}})
+	s.Require().NoError(err)
+
+	tentative := confirmed(1, "person", 100, 50)
+	tentative.State = track.Tentative
+	s.Empty(counter.Update([]track.Track{tentative}, s.start))
+
+	s.Empty(counter.Update([]track.Track{confirmed(1, "person", 100, 50)}, s.start))
+	s.Empty(counter.Update([]track.Track{}, s.start))
+	// The track was forgotten, so its previous side is unknown
+	s.Empty(counter.Update([]track.Track{confirmed(1, "person", 100, 150)}, s.start))
+}
+
+func (s *LineTestSuite) TestDirectionString() {
+	s.Equal("in", In.String())
+	s.Equal("out", Out.String())
+}
@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"image"
+	"math"
+)
+
+// Polygon a closed polygon, the last point is connected to the first one.
+type Polygon []image.Point
+
+// Contains reports whether given point is inside the polygon, using the even-odd rule.
+func (p Polygon) Contains(pt image.Point) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) == (b.Y > pt.Y) {
+			continue
+		}
+		x := float64(b.X-a.X)*float64(pt.Y-a.Y)/float64(b.Y-a.Y) + float64(a.X)
+		if float64(pt.X) < x {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Area returns the area of the polygon in square pixels, the polygon should not intersect itself.
+func (p Polygon) Area() float64 {
+	return area(p.points())
+}
+
+// OverlapRatio returns the fraction of the polygon area which is covered by given box.
+func (p Polygon) OverlapRatio(box image.Rectangle) float64 {
+	total := p.Area()
+	if total == 0 {
+		return 0
+	}
+	points := p.points()
+
+	// Clip the polygon against every edge of the box using the Sutherland-Hodgman algorithm
+	minX, minY, maxX, maxY := float64(box.Min.X), float64(box.Min.Y), float64(box.Max.X), float64(box.Max.Y)
+	points = clip(points, func(q point) float64 { return q.x - minX })
+	points = clip(points, func(q point) float64 { return maxX - q.x })
+	points = clip(points, func(q point) float64 { return q.y - minY })
+	points = clip(points, func(q point) float64 { return maxY - q.y })
+	return area(points) / total
+}
+
+type point struct {
+	x, y float64
+}
+
+func (p Polygon) points() []point {
+	points := make([]point, len(p))
+	for i, pt := range p {
+		points[i] = point{float64(pt.X), float64(pt.Y)}
+	}
+	return points
+}
+
+// clip returns the part of the polygon for which inside is not negative, inside should be a
+// linear function which is positive on the inner side of the clipping edge.
+func clip(polygon []point, inside func(point) float64) []point {
+	res := []point{}
+	for i := range polygon {
+		cur, prev := polygon[i], polygon[(i+len(polygon)-1)%len(polygon)]
+		dc, dp := inside(cur), inside(prev)
+		if (dc >= 0) != (dp >= 0) {
+			t := dp / (dp - dc)
+			res = append(res, point{prev.x + t*(cur.x-prev.x), prev.y + t*(cur.y-prev.y)})
+		}
+		if dc >= 0 {
+			res = append(res, cur)
+		}
+	}
+	return res
+}
+
+// area returns the area of the polygon using the shoelace formula.
+func area(polygon []point) float64 {
+	sum := 0.0
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		sum += a.x*b.y - b.x*a.y
+	}
+	return math.Abs(sum) / 2
+}
@@ -0,0 +1,186 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wimspaargaren/yolov5/detect"
+)
+
+// DefaultMinOverlap is the default fraction of a slot which should be covered for it to be occupied.
+const DefaultMinOverlap = 0.5
+
+// VehicleClasses returns the COCO class names of vehicles, which occupy slots by default.
+func VehicleClasses() map[string]bool {
+	return map[string]bool{"car": true, "truck": true, "bus": true, "motorcycle": true}
+}
+
+// SlotState the occupancy state of a slot.
+type SlotState int
+
+// Possible states of a slot.
+const (
+	Free SlotState = iota
+	Occupied
+)
+
+// String returns the name of the state.
+func (s SlotState) String() string {
+	if s == Occupied {
+		return "occupied"
+	}
+	return "free"
+}
+
+// Slot a named polygon which can be occupied by a single object, such as a parking spot.
+type Slot struct {
+	Name    string
+	Polygon Polygon
+}
+
+// SlotEvent a change of the state of a slot.
+type SlotEvent struct {
+	Slot string
+	// State is the new state of the slot
+	State     SlotState
+	Timestamp time.Time
+	// Duration is the time the slot spent in its previous state
+	Duration time.Duration
+	// Overlap is the fraction of the slot covered by the detection which occupies it, it is zero
+	// when the slot became free
+	Overlap float64
+}
+
+// SlotMonitorConfig can be used to configure a slot monitor.
+type SlotMonitorConfig struct {
+	Slots []Slot
+	// Classes contains the class names which occupy slots, it defaults to VehicleClasses so
+	// people and bags passing through a slot do not occupy it
+	Classes map[string]bool
+	// MinOverlap is the fraction of the slot area a bounding box should cover to occupy it
+	MinOverlap float64
+	// Debounce is the time a slot should be observed in a new state before the state changes,
+	// so a single missed detection or a passing object does not change the state
+	Debounce time.Duration
+}
+
+// slotState the state of a single slot.
+type slotState struct {
+	slot    Slot
+	state   SlotState
+	since   time.Time
+	pending bool
+	// changed is the moment the slot was first observed in the pending state
+	changed time.Time
+}
+
+// SlotMonitor determines the occupancy of slots. All slots are free before the first update.
+// The monitor is not safe for concurrent use.
+type SlotMonitor struct {
+	config  SlotMonitorConfig
+	slots   []*slotState
+	byName  map[string]*slotState
+	started bool
+}
+
+// NewSlotMonitor creates a slot monitor for given config, zero values are replaced with their defaults.
+func NewSlotMonitor(config SlotMonitorConfig) (*SlotMonitor, error) {
+	if len(config.Classes) == 0 {
+		config.Classes = VehicleClasses()
+	}
+	if config.MinOverlap == 0 {
+		config.MinOverlap = DefaultMinOverlap
+	}
+	if config.MinOverlap < 0 || config.MinOverlap > 1 {
+		return nil, fmt.Errorf("min overlap should be between 0 and 1, got %v", config.MinOverlap)
+	}
+	if config.Debounce < 0 {
+		return nil, fmt.Errorf("debounce should not be negative, got %v", config.Debounce)
+	}
+
+	monitor := &SlotMonitor{
+		config: config,
+		byName: map[string]*slotState{},
+	}
+	for _, slot := range config.Slots {
+		if len(slot.Polygon) < 3 || slot.Polygon.Area() == 0 {
+			return nil, fmt.Errorf("polygon of slot %q should have at least 3 points and a non zero area", slot.Name)
+		}
+		if _, ok := monitor.byName[slot.Name]; ok {
+			return nil, fmt.Errorf("slot name %q is used more than once", slot.Name)
+		}
+		state := &slotState{slot: slot}
+		monitor.slots = append(monitor.slots, state)
+		monitor.byName[slot.Name] = state
+	}
+	return monitor, nil
+}
+
+// Update processes the detections of the next frame and returns the state changes, in the order
+// the slots were configured.
+func (m *SlotMonitor) Update(detections []detect.ObjectDetection, timestamp time.Time) []SlotEvent {
+	if !m.started {
+		for _, s := range m.slots {
+			s.since = timestamp
+		}
+		m.started = true
+	}
+
+	events := []SlotEvent{}
+	for _, s := range m.slots {
+		overlap := 0.0
+		for _, detection := range detections {
+			if !m.config.Classes[detection.ClassName] {
+				continue
+			}
+			overlap = max(overlap, s.slot.Polygon.OverlapRatio(detection.BoundingBox))
+		}
+		observed := Free
+		if overlap >= m.config.MinOverlap {
+			observed = Occupied
+		}
+
+		if observed == s.state {
+			s.pending = false
+			continue
+		}
+		if !s.pending {
+			s.pending, s.changed = true, timestamp
+		}
+		if timestamp.Sub(s.changed) < m.config.Debounce {
+			continue
+		}
+		if observed == Free {
+			overlap = 0
+		}
+		events = append(events, SlotEvent{
+			Slot:      s.slot.Name,
+			State:     observed,
+			Timestamp: timestamp,
+			Duration:  s.changed.Sub(s.since),
+			Overlap:   overlap,
+		})
+		s.state, s.since, s.pending = observed, s.changed, false
+	}
+	return events
+}
+
+// State returns the state of given slot and the moment it changed into that state.
+func (m *SlotMonitor) State(slot string) (SlotState, time.Time, bool) {
+	s, ok := m.byName[slot]
+	if !ok {
+		return Free, time.Time{}, false
+	}
+	return s.state, s.since, true
+}
+
+// Occupied returns the amount of occupied slots.
+func (m *SlotMonitor) Occupied() int {
+	count := 0
+	for _, s := range m.slots {
+		if s.state == Occupied {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/detect"
+)
+
+type SlotTestSuite struct {
+	suite.Suite
+	start time.Time
+}
+
+func TestSlotTestSuite(t *testing.T) {
+	suite.Run(t, new(SlotTestSuite))
+}
+
+func (s *SlotTestSuite) SetupTest() {
+	s.start = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *SlotTestSuite) at(seconds int) time.Time {
+	return s.start.Add(time.Duration(seconds) * time.Second)
+}
+
+// spot returns a slot with corners (0, 0) and (100, 100).
+func spot(name string) Slot {
+	return Slot{
+		Name:    name,
+		Polygon: Polygon{image.Pt(0, 0), image.Pt(100, 0), image.Pt(100, 100), image.Pt(0, 100)},
+	}
+}
+
+func car(box image.Rectangle) detect.ObjectDetection {
+	return detect.ObjectDetection{ClassName: "car", BoundingBox: box}
+}
+
+func (s *SlotTestSuite) TestPolygonOverlapRatio() {
+	square := spot("a").Polygon
+	s.InDelta(10000, square.Area(), 1e-9)
+	s.InDelta(1, square.OverlapRatio(image.Rect(-10, -10, 110, 110)), 1e-9)
+	s.InDelta(0.5, square.OverlapRatio(image.Rect(50, -10, 150, 110)), 1e-9)
+	s.InDelta(0.25, square.OverlapRatio(image.Rect(50, 50, 150, 150)), 1e-9)
+	s.InDelta(0, square.OverlapRatio(image.Rect(200, 200, 300, 300)), 1e-9)
+
+	triangle := Polygon{image.Pt(0, 0), image.Pt(100, 0), image.Pt(0, 100)}
+	s.InDelta(5000, triangle.Area(), 1e-9)
+	s.InDelta(0.75, triangle.OverlapRatio(image.Rect(0, 0, 50, 100)), 1e-9)
+}
+
+func (s *SlotTestSuite) TestNewSlotMonitorInvalidConfig() {
+	_, err := NewSlotMonitor(SlotMonitorConfig{Slots: []Slot{{Name: "line", Polygon: Polygon{image.Pt(0, 0), image.Pt(1, 1), image.Pt(2, 2)}}}})
+	s.Error(err)
+	_, err = NewSlotMonitor(SlotMonitorConfig{Slots: []Slot{spot("a"), spot("a")}})
+	s.Error(err)
+	_, err = NewSlotMonitor(SlotMonitorConfig{MinOverlap: 1.5})
+	s.Error(err)
+	_, err = NewSlotMonitor(SlotMonitorConfig{Debounce: -time.Second})
+	s.Error(err)
+}
+
+func (s *SlotTestSuite) TestDebouncedStateChanges() {
+	monitor, err := NewSlotMonitor(SlotMonitorConfig{
+		Slots:    []Slot{spot("a1")},
+		Classes:  map[string]bool{"car": true},
+		Debounce: 2 * time.Second,
+	})
+	s.Require().NoError(err)
+
+	parked := []detect.ObjectDetection{car(image.Rect(10, 10, 90, 110))}
+	s.Empty(monitor.Update(nil, s.at(0)))
+	s.Empty(monitor.Update(parked, s.at(10)))
+	// The car is only seen for a moment, the slot stays free
+	s.Empty(monitor.Update(nil, s.at(11)))
+	s.Empty(monitor.Update(parked, s.at(12)))
+	s.Equal([]SlotEvent{
+		{Slot: "a1", State: Occupied, Timestamp: s.at(14), Duration: 12 * time.Second, Overlap: 0.8 * 0.9},
+	}, monitor.Update(parked, s.at(14)))
+	s.Equal(1, monitor.Occupied())
+
+	// A single missed detection does not free the slot
+	s.Empty(monitor.Update(nil, s.at(30)))
+	s.Empty(monitor.Update(parked, s.at(31)))
+	s.Empty(monitor.Update(nil, s.at(60)))
+	s.Equal([]SlotEvent{
+		{Slot: "a1", State: Free, Timestamp: s.at(62), Duration: 48 * time.Second},
+	}, monitor.Update(nil, s.at(62)))
+
+	state, since, ok := monitor.State("a1")
+	s.True(ok)
+	s.Equal(Free, state)
+	s.Equal(s.at(60), since)
+	_, _, ok = monitor.State("unknown")
+	s.False(ok)
+}
+
+func (s *SlotTestSuite) TestOverlapAndClasses() {
+	monitor, err := NewSlotMonitor(SlotMonitorConfig{
+		Slots:   []Slot{spot("a1")},
+		Classes: map[string]bool{"car": true},
+	})
+	s.Require().NoError(err)
+
+	// A car in the next slot which covers a small part of this slot
+	s.Empty(monitor.Update([]detect.ObjectDetection{car(image.Rect(80, 0, 180, 100))}, s.at(0)))
+	person := detect.ObjectDetection{ClassName: "person", BoundingBox: image.Rect(0, 0, 100, 100)}
+	s.Empty(monitor.Update([]detect.ObjectDetection{person}, s.at(1)))
+
+	events := monitor.Update([]detect.ObjectDetection{car(image.Rect(40, 0, 140, 100))}, s.at(2))
+	s.Require().Len(events, 1)
+	s.Equal(Occupied, events[0].State)
+	s.InDelta(0.6, events[0].Overlap, 1e-9)
+}
+
+func (s *SlotTestSuite) TestDefaultClasses() {
+	monitor, err := NewSlotMonitor(SlotMonitorConfig{Slots: []Slot{spot("a1")}})
+	s.Require().NoError(err)
+
+	person := detect.ObjectDetection{ClassName: "person", BoundingBox: image.Rect(0, 0, 100, 100)}
+	s.Empty(monitor.Update([]detect.ObjectDetection{person}, s.at(0)))
+	truck := detect.ObjectDetection{ClassName: "truck", BoundingBox: image.Rect(0, 0, 100, 100)}
+	s.Len(monitor.Update([]detect.ObjectDetection{truck}, s.at(1)), 1)
+}
+
+func (s *SlotTestSuite) TestSlotStateString() {
+	s.Equal("free", Free.String())
+	s.Equal("occupied", Occupied.String())
+}
@@ -0,0 +1,275 @@
+package analytics
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wimspaargaren/yolov5/internal/postprocess"
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+// Default constants for detecting stationary objects.
+const (
+	DefaultMaxDisplacement         = 10.0
+	DefaultDwell                   = 30 * time.Second
+	DefaultRemovedAfter            = 5 * time.Second
+	DefaultRematchIoU      float32 = 0.5
+)
+
+// StationaryEventType the type of a stationary object event.
+type StationaryEventType int
+
+// Possible types of stationary object events.
+const (
+	// Abandoned is emitted when a new object stayed at the same position for the dwell time
+	Abandoned StationaryEventType = iota
+	// Removed is emitted when a stationary object disappeared from the scene or was carried away
+	Removed
+)
+
+// String returns the name of the event type.
+func (t StationaryEventType) String() string {
+	switch t {
+	case Abandoned:
+		return "abandoned"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// StationaryEvent an event which occurred for a stationary object.
+type StationaryEvent struct {
+	Type        StationaryEventType
+	TrackID     int
+	ClassName   string
+	Timestamp   time.Time
+	BoundingBox image.Rectangle
+	// Since is the moment the object became stationary
+	Since time.Time
+	// Snapshot is a crop of the object at the moment it was found to be stationary, it is nil
+	// when no frame was provided
+	Snapshot image.Image
+}
+
+// StationaryConfig can be used to configure a stationary object detector.
+type StationaryConfig struct {
+	// Classes contains the class names which are monitored, all classes are monitored when empty
+	Classes map[string]bool
+	// Anchor is the point of the bounding box of which the displacement is measured
+	Anchor Anchor
+	// MaxDisplacement is the distance in pixels an object may move while still being stationary
+	MaxDisplacement float64
+	// Dwell is the time an object should be stationary before it is reported as abandoned
+	Dwell time.Duration
+	// RemovedAfter is the time a stationary object should be missing before it is reported as removed
+	RemovedAfter time.Duration
+	// LearnPeriod is the time after the first update in which stationary objects are considered to
+	// be part of the scene, these are not reported as abandoned but are reported when removed
+	LearnPeriod time.Duration
+	// RematchIoU is the minimum intersection over union between a missing stationary object and a
+	// new track for the track to be considered the same object, for example after an occlusion
+	RematchIoU float32
+}
+
+// stationaryObject the state of a monitored object.
+type stationaryObject struct {
+	className string
+	box       image.Rectangle
+	// anchor is the position at which the object was first seen since it last moved
+	anchor       image.Point
+	since        time.Time
+	stationary   bool
+	snapshot     image.Image
+	missing      bool
+	missingSince time.Time
+}
+
+// StationaryDetector detects objects which are left behind or taken away.
+// The detector is not safe for concurrent use.
+type StationaryDetector struct {
+	config  StationaryConfig
+	start   time.Time
+	started bool
+	objects map[int]*stationaryObject
+}
+
+// NewStationaryDetector creates a stationary object detector for given config, zero values are
+// replaced with their defaults.
+func NewStationaryDetector(config StationaryConfig) (*StationaryDetector, error) {
+	if config.MaxDisplacement == 0 {
+		config.MaxDisplacement = DefaultMaxDisplacement
+	}
+	if config.Dwell == 0 {
+		config.Dwell = DefaultDwell
+	}
+	if config.RemovedAfter == 0 {
+		config.RemovedAfter = DefaultRemovedAfter
+	}
+	if config.RematchIoU == 0 {
+		config.RematchIoU = DefaultRematchIoU
+	}
+	if config.MaxDisplacement < 0 {
+		return nil, fmt.Errorf("max displacement should not be negative, got %v", config.MaxDisplacement)
+	}
+	if config.Dwell < 0 || config.RemovedAfter < 0 || config.LearnPeriod < 0 {
+		return nil, fmt.Errorf("dwell, removed after and learn period should not be negative")
+	}
+	if config.RematchIoU < 0 || config.RematchIoU > 1 {
+		return nil, fmt.Errorf("rematch IoU should be between 0 and 1, got %v", config.RematchIoU)
+	}
+	return &StationaryDetector{
+		config:  config,
+		objects: map[int]*stationaryObject{},
+	}, nil
+}
+
+// Update processes the tracks of the next frame and returns the events which occurred. The frame
+// is used to crop snapshots of the objects and may be nil. Only confirmed tracks are monitored,
+// a stationary object of which the track is lost or gone is considered missing.
+func (d *StationaryDetector) Update(tracks []track.Track, frame image.Image, timestamp time.Time) []StationaryEvent {
+	if !d.started {
+		d.start, d.started = timestamp, true
+	}
+
+	visible := []track.Track{}
+	present := map[int]bool{}
+	for _, t := range tracks {
+		if t.State == track.Confirmed && matchesClass(d.config.Classes, t) {
+			visible = append(visible, t)
+			present[t.ID] = true
+		}
+	}
+
+	events := []StationaryEvent{}
+	for _, t := range visible {
+		o, ok := d.objects[t.ID]
+		if !ok {
+			o = d.rematch(t, present)
+			d.objects[t.ID] = o
+		}
+		if event, ok := d.updateObject(t, o, frame, timestamp); ok {
+			events = append(events, event)
+		}
+	}
+
+	ids := make([]int, 0, len(d.objects))
+	for id := range d.objects {
+		if !present[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		o := d.objects[id]
+		if !o.stationary {
+			delete(d.objects, id)
+			continue
+		}
+		if !o.missing {
+			o.missing, o.missingSince = true, timestamp
+		}
+		if timestamp.Sub(o.missingSince) >= d.config.RemovedAfter {
+			events = append(events, o.event(Removed, id, timestamp))
+			delete(d.objects, id)
+		}
+	}
+	return events
+}
+
+// rematch returns the missing stationary object which overlaps with given new track, or a new object.
+func (d *StationaryDetector) rematch(t track.Track, present map[int]bool) *stationaryObject {
+	best, bestIoU := -1, d.config.RematchIoU
+	for id, o := range d.objects {
+		if present[id] || !o.stationary || o.className != t.Detection.ClassName {
+			continue
+		}
+		iou := postprocess.IoU(o.box, t.BoundingBox)
+		if iou > bestIoU || iou == bestIoU && (best < 0 || id < best) {
+			best, bestIoU = id, iou
+		}
+	}
+	if best < 0 {
+		return &stationaryObject{className: t.Detection.ClassName}
+	}
+	o := d.objects[best]
+	delete(d.objects, best)
+	return o
+}
+
+// updateObject updates the state of an object with its track, it returns an event when the
+// object became stationary or when a stationary object is carried away.
+func (d *StationaryDetector) updateObject(t track.Track, o *stationaryObject, frame image.Image, timestamp time.Time) (StationaryEvent, bool) {
+	point := d.config.Anchor.Point(t.BoundingBox)
+	o.missing = false
+	if o.since.IsZero() || distance(point, o.anchor) > d.config.MaxDisplacement {
+		// The event describes the object where it was stationary, so it is created before resetting
+		event, moved := StationaryEvent{}, o.stationary
+		if moved {
+			event = o.event(Removed, t.ID, timestamp)
+		}
+		o.box, o.anchor, o.since, o.stationary, o.snapshot = t.BoundingBox, point, timestamp, false, nil
+		return event, moved
+	}
+	o.box = t.BoundingBox
+	if o.stationary || timestamp.Sub(o.since) < d.config.Dwell {
+		return StationaryEvent{}, false
+	}
+
+	o.stationary = true
+	o.snapshot = crop(frame, t.BoundingBox)
+	if o.since.Sub(d.start) < d.config.LearnPeriod {
+		return StationaryEvent{}, false
+	}
+	return o.event(Abandoned, t.ID, timestamp), true
+}
+
+// Stationary returns the IDs of the tracks which are currently considered stationary, including
+// the missing ones.
+func (d *StationaryDetector) Stationary() []int {
+	ids := []int{}
+	for id, o := range d.objects {
+		if o.stationary {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// event creates an event for the object.
+func (o *stationaryObject) event(eventType StationaryEventType, trackID int, timestamp time.Time) StationaryEvent {
+	return StationaryEvent{
+		Type:        eventType,
+		TrackID:     trackID,
+		ClassName:   o.className,
+		Timestamp:   timestamp,
+		BoundingBox: o.box,
+		Since:       o.since,
+		Snapshot:    o.snapshot,
+	}
+}
+
+// crop copies the part of the frame inside given box, so the snapshot does not change when the
+// frame buffer is reused.
+func crop(frame image.Image, box image.Rectangle) image.Image {
+	if frame == nil {
+		return nil
+	}
+	r := box.Intersect(frame.Bounds())
+	if r.Empty() {
+		return nil
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), frame, r.Min, draw.Src)
+	return dst
+}
+
+func distance(a, b image.Point) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+type StationaryTestSuite struct {
+	suite.Suite
+	start time.Time
+	frame *image.RGBA
+}
+
+func TestStationaryTestSuite(t *testing.T) {
+	suite.Run(t, new(StationaryTestSuite))
+}
+
+func (s *StationaryTestSuite) SetupTest() {
+	s.start = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.frame = image.NewRGBA(image.Rect(0, 0, 320, 240))
+	s.frame.Set(100, 100, color.RGBA{255, 0, 0, 255})
+}
+
+func (s *StationaryTestSuite) at(seconds int) time.Time {
+	return s.start.Add(time.Duration(seconds) * time.Second)
+}
+
+func (s *StationaryTestSuite) detector(config StationaryConfig) *StationaryDetector {
+	if config.Dwell == 0 {
+		config.Dwell = 3 * time.Second
+	}
+	if config.RemovedAfter == 0 {
+		config.RemovedAfter = 2 * time.Second
+	}
+	detector, err := NewStationaryDetector(config)
+	s.Require().NoError(err)
+	return detector
+}
+
+func (s *StationaryTestSuite) TestNewStationaryDetectorInvalidConfig() {
+	_, err := NewStationaryDetector(StationaryConfig{MaxDisplacement: -1})
+	s.Error(err)
+	_, err = NewStationaryDetector(StationaryConfig{Dwell: -time.Second})
+	s.Error(err)
+	_, err = NewStationaryDetector(StationaryConfig{RematchIoU: 2})
+	s.Error(err)
+}
+
+func (s *StationaryTestSuite) TestAbandoned() {
+	detector := s.detector(StationaryConfig{})
+
+	for second := 0; second < 3; second++ {
+		// The bag jitters a few pixels
+		s.Empty(detector.Update([]track.Track{confirmed(1, "backpack", 100+second, 120)}, s.frame, s.at(second)))
+	}
+	events := detector.Update([]track.Track{confirmed(1, "backpack", 100, 120)}, s.frame, s.at(3))
+	s.Require().Len(events, 1)
+	s.Equal(Abandoned, events[0].Type)
+	s.Equal(1, events[0].TrackID)
+	s.Equal("backpack", events[0].ClassName)
+	s.Equal(s.at(0), events[0].Since)
+	s.Equal(image.Rect(90, 80, 110, 120), events[0].BoundingBox)
+	s.Require().NotNil(events[0].Snapshot)
+	s.Equal(image.Rect(0, 0, 20, 40), events[0].Snapshot.Bounds())
+	s.Equal(color.RGBA{255, 0, 0, 255}, events[0].Snapshot.At(10, 20))
+
+	// The event is emitted once
+	s.Empty(detector.Update([]track.Track{confirmed(1, "backpack", 100, 120)}, s.frame, s.at(4)))
+	s.Equal([]int{1}, detector.Stationary())
+}
+
+func (s *StationaryTestSuite) TestMovingObjects() {
+	detector := s.detector(StationaryConfig{})
+	for second := 0; second < 10; second++ {
+		s.Empty(detector.Update([]track.Track{confirmed(1, "person", 100+20*second, 120)}, nil, s.at(second)))
+	}
+
+	// An object which stops moving becomes stationary from the moment it stopped
+	for second := 10; second < 13; second++ {
+		s.Empty(detector.Update([]track.Track{confirmed(1, "person", 300, 120)}, nil, s.at(second)))
+	}
+	events := detector.Update([]track.Track{confirmed(1, "person", 300, 120)}, nil, s.at(13))
+	s.Require().Len(events, 1)
+	s.Equal(s.at(10), events[0].Since)
+	s.Nil(events[0].Snapshot)
+}
+
+func (s *StationaryTestSuite) TestRemoved() {
+	detector := s.detector(StationaryConfig{LearnPeriod: 5 * time.Second})
+
+	// Objects which are stationary in the learn period are part of the scene
+	for second := 0; second < 5; second++ {
+		s.Empty(detector.Update([]track.Track{confirmed(1, "suitcase", 100, 120)}, s.frame, s.at(second)))
+	}
+	s.Equal([]int{1}, detector.Stationary())
+
+	// Missing, but not for long enough
+	lost := confirmed(1, "suitcase", 100, 120)
+	lost.State = track.Lost
+	s.Empty(detector.Update([]track.Track{lost}, s.frame, s.at(5)))
+	s.Empty(detector.Update([]track.Track{}, s.frame, s.at(6)))
+
+	events := detector.Update([]track.Track{}, s.frame, s.at(7))
+	s.Require().Len(events, 1)
+	s.Equal(Removed, events[0].Type)
+	s.Equal(1, events[0].TrackID)
+	s.Equal(s.at(7), events[0].Timestamp)
+	s.NotNil(events[0].Snapshot)
+	s.Empty(detector.Stationary())
+}
+
+func (s *StationaryTestSuite) TestCarriedAway() {
+	detector := s.detector(StationaryConfig{LearnPeriod: 5 * time.Second})
+	for second := 0; second < 5; second++ {
+		detector.Update([]track.Track{confirmed(1, "suitcase", 100, 120)}, s.frame, s.at(second))
+	}
+
+	// The suitcase is picked up and keeps its track while it is carried away
+	events := detector.Update([]track.Track{confirmed(1, "suitcase", 130, 125)}, s.frame, s.at(5))
+	s.Require().Len(events, 1)
+	s.Equal(Removed, events[0].Type)
+	s.Equal(1, events[0].TrackID)
+	s.Equal(s.at(0), events[0].Since)
+	s.Equal(image.Rect(90, 80, 110, 120), events[0].BoundingBox)
+	s.NotNil(events[0].Snapshot)
+	s.Empty(detector.Stationary())
+
+	// Leaving the scene afterwards does not report it again
+	s.Empty(detector.Update([]track.Track{confirmed(1, "suitcase", 200, 125)}, s.frame, s.at(6)))
+	s.Empty(detector.Update([]track.Track{}, s.frame, s.at(10)))
+}
+
+func (s *StationaryTestSuite) TestRematchAfterOcclusion() {
+	detector := s.detector(StationaryConfig{LearnPeriod: 5 * time.Second})
+	for second := 0; second < 4; second++ {
+		detector.Update([]track.Track{confirmed(1, "suitcase", 100, 120)}, nil, s.at(second))
+	}
+
+	// The object is occluded and gets a new track ID afterwards
+	s.Empty(detector.Update([]track.Track{}, nil, s.at(4)))
+	s.Empty(detector.Update([]track.Track{confirmed(2, "suitcase", 101, 120)}, nil, s.at(5)))
+	s.Equal([]int{2}, detector.Stationary())
+	s.Empty(detector.Update([]track.Track{confirmed(2, "suitcase", 101, 120)}, nil, s.at(10)))
+}
+
+func (s *StationaryTestSuite) TestClasses() {
+	detector := s.detector(StationaryConfig{Classes: map[string]bool{"backpack": true}})
+	for second := 0; second <= 3; second++ {
+		events := detector.Update([]track.Track{confirmed(1, "person", 100, 120)}, nil, s.at(second))
+		s.Empty(events)
+	}
+}
+
+func (s *StationaryTestSuite) TestStationaryEventTypeString() {
+	s.Equal("abandoned", Abandoned.String())
+	s.Equal("removed", Removed.String())
+}
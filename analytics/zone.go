@@ -0,0 +1,257 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+// DefaultStatsWindow is the default duration over which occupancy statistics are calculated.
+const DefaultStatsWindow = time.Minute
+
+// Zone a named polygon in which the occupancy and dwell time of objects is monitored.
+type Zone struct {
+	Name    string
+	Polygon Polygon
+	// Classes contains the class names which are monitored, all classes are monitored when empty
+	Classes map[string]bool
+	// MaxDwell is the dwell time after which a DwellExceeded event is emitted, zero disables the event
+	MaxDwell time.Duration
+}
+
+// ZoneEventType the type of a zone event.
+type ZoneEventType int
+
+// Possible types of zone events.
+const (
+	Enter ZoneEventType = iota
+	Exit
+	DwellExceeded
+)
+
+// String returns the name of the event type.
+func (t ZoneEventType) String() string {
+	switch t {
+	case Enter:
+		return "enter"
+	case Exit:
+		return "exit"
+	case DwellExceeded:
+		return "dwell_exceeded"
+	default:
+		return "unknown"
+	}
+}
+
+// ZoneEvent an event which occurred for a track in a zone.
+type ZoneEvent struct {
+	Type      ZoneEventType
+	Zone      string
+	TrackID   int
+	ClassName string
+	Timestamp time.Time
+	// Dwell is the time the track spent in the zone so far
+	Dwell time.Duration
+}
+
+// OccupancyStats the occupancy of a class in a zone.
+type OccupancyStats struct {
+	// Current is the amount of objects in the zone at the last update
+	Current int
+	// Min, Max & Mean are calculated over the updates within the window
+	Min  int
+	Max  int
+	Mean float64
+}
+
+// ZoneEngineConfig can be used to configure a zone engine.
+type ZoneEngineConfig struct {
+	Zones []Zone
+	// Anchor is the point of the bounding box which should be inside the zone
+	Anchor Anchor
+	// Window is the duration over which occupancy statistics are calculated
+	Window time.Duration
+}
+
+// visit a track which is inside a zone.
+type visit struct {
+	className     string
+	entered       time.Time
+	dwellExceeded bool
+}
+
+// occupancySample the occupancy of a zone per class at a moment in time.
+type occupancySample struct {
+	timestamp time.Time
+	counts    map[string]int
+}
+
+// zoneState the tracks inside a zone and its recent occupancy.
+type zoneState struct {
+	zone    Zone
+	visits  map[int]*visit
+	samples []occupancySample
+	classes map[string]bool
+}
+
+// ZoneEngine monitors the occupancy and dwell time of tracks in zones.
+// The engine is not safe for concurrent use.
+type ZoneEngine struct {
+	anchor Anchor
+	window time.Duration
+	zones  []*zoneState
+	byName map[string]*zoneState
+}
+
+// NewZoneEngine creates a zone engine for given config.
+func NewZoneEngine(config ZoneEngineConfig) (*ZoneEngine, error) {
+	if config.Window == 0 {
+		config.Window = DefaultStatsWindow
+	}
+	if config.Window < 0 {
+		return nil, fmt.Errorf("statistics window should not be negative, got %v", config.Window)
+	}
+
+	engine := &ZoneEngine{
+		anchor: config.Anchor,
+		window: config.Window,
+		byName: map[string]*zoneState{},
+	}
+	for _, zone := range config.Zones {
+		if len(zone.Polygon) < 3 {
+			return nil, fmt.Errorf("polygon of zone %q should have at least 3 points, got %d", zone.Name, len(zone.Polygon))
+		}
+		if _, ok := engine.byName[zone.Name]; ok {
+			return nil, fmt.Errorf("zone name %q is used more than once", zone.Name)
+		}
+		state := &zoneState{
+			zone:    zone,
+			visits:  map[int]*visit{},
+			classes: map[string]bool{},
+		}
+		engine.zones = append(engine.zones, state)
+		engine.byName[zone.Name] = state
+	}
+	return engine, nil
+}
+
+// Update processes the tracks of the next frame and returns the events which occurred.
+// Confirmed tracks enter and exit zones, lost tracks keep their last known zones and
+// tracks which are no longer present exit the zones they were in.
+func (e *ZoneEngine) Update(tracks []track.Track, timestamp time.Time) []ZoneEvent {
+	events := []ZoneEvent{}
+	for _, state := range e.zones {
+		events = append(events, e.updateZone(state, tracks, timestamp)...)
+	}
+	return events
+}
+
+// updateZone processes the tracks for a single zone.
+func (e *ZoneEngine) updateZone(state *zoneState, tracks []track.Track, timestamp time.Time) []ZoneEvent {
+	events := []ZoneEvent{}
+	present := make(map[int]bool, len(tracks))
+	for _, t := range tracks {
+		present[t.ID] = true
+		if t.State != track.Confirmed || !matchesClass(state.zone.Classes, t) {
+			continue
+		}
+
+		inside := state.zone.Polygon.Contains(e.anchor.Point(t.BoundingBox))
+		v, visiting := state.visits[t.ID]
+		switch {
+		case inside && !visiting:
+			state.visits[t.ID] = &visit{className: t.Detection.ClassName, entered: timestamp}
+			state.classes[t.Detection.ClassName] = true
+			events = append(events, ZoneEvent{Type: Enter, Zone: state.zone.Name, TrackID: t.ID, ClassName: t.Detection.ClassName, Timestamp: timestamp})
+		case !inside && visiting:
+			delete(state.visits, t.ID)
+			events = append(events, state.event(Exit, t.ID, v, timestamp))
+		case inside && visiting && state.zone.MaxDwell > 0 && !v.dwellExceeded && timestamp.Sub(v.entered) >= state.zone.MaxDwell:
+			v.dwellExceeded = true
+			events = append(events, state.event(DwellExceeded, t.ID, v, timestamp))
+		}
+	}
+
+	ids := make([]int, 0, len(state.visits))
+	for id := range state.visits {
+		if !present[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		events = append(events, state.event(Exit, id, state.visits[id], timestamp))
+		delete(state.visits, id)
+	}
+
+	state.sample(timestamp, e.window)
+	return events
+}
+
+// event creates an event for given visit.
+func (s *zoneState) event(eventType ZoneEventType, trackID int, v *visit, timestamp time.Time) ZoneEvent {
+	return ZoneEvent{
+		Type:      eventType,
+		Zone:      s.zone.Name,
+		TrackID:   trackID,
+		ClassName: v.className,
+		Timestamp: timestamp,
+		Dwell:     timestamp.Sub(v.entered),
+	}
+}
+
+// sample records the current occupancy and drops the samples which fell out of the window.
+func (s *zoneState) sample(timestamp time.Time, window time.Duration) {
+	counts := map[string]int{}
+	for _, v := range s.visits {
+		counts[v.className]++
+	}
+	s.samples = append(s.samples, occupancySample{timestamp: timestamp, counts: counts})
+
+	drop := 0
+	for drop < len(s.samples)-1 && timestamp.Sub(s.samples[drop].timestamp) > window {
+		drop++
+	}
+	s.samples = s.samples[drop:]
+}
+
+// Occupancy returns the amount of tracks of every class which are currently in given zone.
+func (e *ZoneEngine) Occupancy(zone string) map[string]int {
+	occupancy := map[string]int{}
+	state, ok := e.byName[zone]
+	if !ok {
+		return occupancy
+	}
+	for _, v := range state.visits {
+		occupancy[v.className]++
+	}
+	return occupancy
+}
+
+// Stats returns the occupancy statistics of every class which has been seen in given zone.
+func (e *ZoneEngine) Stats(zone string) map[string]OccupancyStats {
+	stats := map[string]OccupancyStats{}
+	state, ok := e.byName[zone]
+	if !ok || len(state.samples) == 0 {
+		return stats
+	}
+
+	for class := range state.classes {
+		s := OccupancyStats{
+			Current: state.samples[len(state.samples)-1].counts[class],
+			Min:     state.samples[0].counts[class],
+		}
+		total := 0
+		for _, sample := range state.samples {
+			count := sample.counts[class]
+			s.Min = min(s.Min, count)
+			s.Max = max(s.Max, count)
+			total += count
+		}
+		s.Mean = float64(total) / float64(len(state.samples))
+		stats[class] = s
+	}
+	return stats
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+type ZoneTestSuite struct {
+	suite.Suite
+	start time.Time
+}
+
+func TestZoneTestSuite(t *testing.T) {
+	suite.Run(t, new(ZoneTestSuite))
+}
+
+func (s *ZoneTestSuite) SetupTest() {
+	s.start = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *ZoneTestSuite) at(seconds int) time.Time {
+	return s.start.Add(time.Duration(seconds) * time.Second)
+}
+
+// square returns a square zone with corners (100, 100) and (200, 200).
+func square(name string) Zone {
+	return Zone{
+		Name:    name,
+		Polygon: Polygon{image.Pt(100, 100), image.Pt(200, 100), image.Pt(200, 200), image.Pt(100, 200)},
+	}
+}
+
+func (s *ZoneTestSuite) TestPolygonContains() {
+	triangle := Polygon{image.Pt(0, 0), image.Pt(100, 0), image.Pt(0, 100)}
+	s.True(triangle.Contains(image.Pt(10, 10)))
+	s.True(triangle.Contains(image.Pt(40, 40)))
+	s.False(triangle.Contains(image.Pt(60, 60)))
+	s.False(triangle.Contains(image.Pt(-10, 10)))
+}
+
+func (s *ZoneTestSuite) TestNewZoneEngineInvalidConfig() {
+	_, err := NewZoneEngine(ZoneEngineConfig{Zones: []Zone{{Name: "line", Polygon: Polygon{image.Pt(0, 0), image.Pt(1, 1)}}}})
+	s.Error(err)
+	_, err = NewZoneEngine(ZoneEngineConfig{Zones: []Zone{square("entrance"), square("entrance")}})
+	s.Error(err)
+	_, err = NewZoneEngine(ZoneEngineConfig{Window: -time.Second})
+	s.Error(err)
+}
+
+func (s *ZoneTestSuite) TestEnterExitAndDwell() {
+	zone := square("entrance")
+	zone.MaxDwell = 3 * time.Second
+	engine, err := NewZoneEngine(ZoneEngineConfig{Zones: []Zone{zone}})
+	s.Require().NoError(err)
+
+	s.Empty(engine.Update([]track.Track{confirmed(1, "person", 50, 150)}, s.at(0)))
+	s.Equal([]ZoneEvent{
+		{Type: Enter, Zone: "entrance", TrackID: 1, ClassName: "person", Timestamp: s.at(1)},
+	}, engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(1)))
+	s.Empty(engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(2)))
+	s.Equal([]ZoneEvent{
+		{Type: DwellExceeded, Zone: "entrance", TrackID: 1, ClassName: "person", Timestamp: s.at(4), Dwell: 3 * time.Second},
+	}, engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(4)))
+	// The dwell event is only emitted once per visit
+	s.Empty(engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(5)))
+	s.Equal([]ZoneEvent{
+		{Type: Exit, Zone: "entrance", TrackID: 1, ClassName: "person", Timestamp: s.at(6), Dwell: 5 * time.Second},
+	}, engine.Update([]track.Track{confirmed(1, "person", 250, 150)}, s.at(6)))
+}
+
+func (s *ZoneTestSuite) TestLostAndRemovedTracks() {
+	engine, err := NewZoneEngine(ZoneEngineConfig{Zones: []Zone{square("entrance")}})
+	s.Require().NoError(err)
+	engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(0))
+
+	lost := confirmed(1, "person", 150, 150)
+	lost.State = track.Lost
+	s.Empty(engine.Update([]track.Track{lost}, s.at(1)))
+	s.Equal(map[string]int{"person": 1}, engine.Occupancy("entrance"))
+
+	s.Equal([]ZoneEvent{
+		{Type: Exit, Zone: "entrance", TrackID: 1, ClassName: "person", Timestamp: s.at(2), Dwell: 2 * time.Second},
+	}, engine.Update([]track.Track{}, s.at(2)))
+	s.Empty(engine.Occupancy("entrance"))
+}
+
+func (s *ZoneTestSuite) TestClasses() {
+	zone := square("parking")
+	zone.Classes = map[string]bool{"car": true}
+	engine, err := NewZoneEngine(ZoneEngineConfig{Zones: []Zone{zone}})
+	s.Require().NoError(err)
+
+	events := engine.Update([]track.Track{
+		confirmed(1, "person", 150, 150),
+		confirmed(2, "car", 160, 160),
+	}, s.at(0))
+	s.Require().Len(events, 1)
+	s.Equal(2, events[0].TrackID)
+	s.Equal(map[string]int{"car": 1}, engine.Occupancy("parking"))
+}
+
+func (s *ZoneTestSuite) TestOccupancyStats() {
+	engine, err := NewZoneEngine(ZoneEngineConfig{
+		Zones:  []Zone{square("entrance"), square("exit")},
+		Window: 2 * time.Second,
+	})
+	s.Require().NoError(err)
+
+	engine.Update([]track.Track{confirmed(1, "person", 150, 150), confirmed(2, "person", 160, 150), confirmed(3, "car", 170, 150)}, s.at(0))
+	engine.Update([]track.Track{confirmed(1, "person", 150, 150), confirmed(3, "car", 170, 150)}, s.at(1))
+	engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(2))
+	s.Equal(map[string]OccupancyStats{
+		"person": {Current: 1, Min: 1, Max: 2, Mean: 4.0 / 3},
+		"car":    {Current: 0, Min: 0, Max: 1, Mean: 2.0 / 3},
+	}, engine.Stats("entrance"))
+
+	// The first sample falls out of the window
+	engine.Update([]track.Track{confirmed(1, "person", 150, 150)}, s.at(3))
+	s.Equal(map[string]OccupancyStats{
+		"person": {Current: 1, Min: 1, Max: 1, Mean: 1},
+		"car":    {Current: 0, Min: 0, Max: 1, Mean: 1.0 / 3},
+	}, engine.Stats("entrance"))
+
+	s.Empty(engine.Stats("unknown"))
+	s.Empty(engine.Occupancy("unknown"))
+}
+
+func (s *ZoneTestSuite) TestZoneEventTypeString() {
+	s.Equal("enter", Enter.String())
+	s.Equal("exit", Exit.String())
+	s.Equal("dwell_exceeded", DwellExceeded.String())
+}
@@ -0,0 +1,32 @@
+package calibration
+
+import (
+	"image"
+	"math"
+)
+
+// BirdsEyeView describes a top down map of a rectangular area of the ground plane, see package
+// birdseye for drawing the map.
+type BirdsEyeView struct {
+	// Min & Max are the corners of the area which is shown, in meters
+	Min Point
+	Max Point
+	// Scale is the amount of pixels per meter
+	Scale float64
+}
+
+// Size returns the size of the map in pixels.
+func (v BirdsEyeView) Size() image.Point {
+	return image.Pt(
+		int(math.Ceil((v.Max.X-v.Min.X)*v.Scale)),
+		int(math.Ceil((v.Max.Y-v.Min.Y)*v.Scale)),
+	)
+}
+
+// ToPixel returns the pixel of the map at which given ground position is shown.
+func (v BirdsEyeView) ToPixel(p Point) image.Point {
+	return image.Pt(
+		int(math.Round((p.X-v.Min.X)*v.Scale)),
+		int(math.Round((p.Y-v.Min.Y)*v.Scale)),
+	)
+}
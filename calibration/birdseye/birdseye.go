@@ -0,0 +1,37 @@
+// Package birdseye draws the measurements of a calibrated camera on a top down map of the ground
+// plane. It is separate from package calibration, so the calibration builds without OpenCV.
+package birdseye
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5/calibration"
+)
+
+// New creates a white image of the size of the view.
+func New(view calibration.BirdsEyeView) gocv.Mat {
+	size := view.Size()
+	return gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 255, 255, 0), size.Y, size.X, gocv.MatTypeCV8UC3)
+}
+
+// Draw draws the positions of given measurements on a bird's-eye map, positions outside of the
+// view are skipped.
+func Draw(img *gocv.Mat, view calibration.BirdsEyeView, measurements []calibration.Measurement) {
+	bounds := image.Rectangle{Max: view.Size()}
+	for _, m := range measurements {
+		pt := view.ToPixel(m.Position)
+		if !pt.In(bounds) {
+			continue
+		}
+		blue := color.RGBA{0, 0, 255, 0}
+		gocv.Circle(img, pt, 5, blue, -1)
+
+		black := color.RGBA{0, 0, 0, 0}
+		text := fmt.Sprintf("%d %s %.1fm/s", m.TrackID, m.ClassName, m.Speed)
+		gocv.PutText(img, text, image.Pt(pt.X+8, pt.Y+4), gocv.FontHersheySimplex, 0.4, black, 1)
+	}
+}
@@ -0,0 +1,163 @@
+package calibration
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+// DefaultSpeedWindow is the default duration over which the speed of a track is estimated.
+const DefaultSpeedWindow = time.Second
+
+// Size the size of an object in meters.
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// Calibration projects bounding boxes onto the ground plane.
+type Calibration struct {
+	homography Homography
+}
+
+// New creates a calibration from given pixel to world point pairs.
+func New(pairs []PointPair) (*Calibration, error) {
+	homography, err := NewHomography(pairs)
+	if err != nil {
+		return nil, err
+	}
+	return &Calibration{homography: homography}, nil
+}
+
+// NewFromHomography creates a calibration from a homography which maps pixels onto the ground plane.
+func NewFromHomography(homography Homography) *Calibration {
+	return &Calibration{homography: homography}
+}
+
+// Homography returns the homography which maps pixels onto the ground plane.
+func (c *Calibration) Homography() Homography {
+	return c.homography
+}
+
+// FootPoint returns the position on the ground plane of the bottom center of given box.
+func (c *Calibration) FootPoint(box image.Rectangle) (Point, error) {
+	return c.homography.Project(Point{X: float64(box.Min.X+box.Max.X) / 2, Y: float64(box.Max.Y)})
+}
+
+// Size estimates the size of the object in given box. The width is the ground distance between
+// the bottom corners of the box, the height is derived from the width using the aspect ratio
+// of the box. This is an approximation which holds for upright objects seen from the side.
+func (c *Calibration) Size(box image.Rectangle) (Size, error) {
+	if box.Dx() <= 0 || box.Dy() <= 0 {
+		return Size{}, fmt.Errorf("bounding box %v is empty", box)
+	}
+	left, err := c.homography.Project(Point{X: float64(box.Min.X), Y: float64(box.Max.Y)})
+	if err != nil {
+		return Size{}, err
+	}
+	right, err := c.homography.Project(Point{X: float64(box.Max.X), Y: float64(box.Max.Y)})
+	if err != nil {
+		return Size{}, err
+	}
+	width := distance(left, right)
+	return Size{Width: width, Height: width * float64(box.Dy()) / float64(box.Dx())}, nil
+}
+
+// Measurement the position, size and speed of a track on the ground plane.
+type Measurement struct {
+	TrackID   int
+	ClassName string
+	Position  Point
+	Size      Size
+	// Speed is the average speed over the estimation window in meters per second
+	Speed float64
+}
+
+// sample the position of a track at a moment in time.
+type sample struct {
+	timestamp time.Time
+	position  Point
+}
+
+// SpeedEstimator estimates the ground speed of tracks.
+// The estimator is not safe for concurrent use.
+type SpeedEstimator struct {
+	calibration *Calibration
+	window      time.Duration
+	history     map[int][]sample
+}
+
+// NewSpeedEstimator creates a speed estimator which averages the speed of tracks over given window.
+func NewSpeedEstimator(calibration *Calibration, window time.Duration) (*SpeedEstimator, error) {
+	if window == 0 {
+		window = DefaultSpeedWindow
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("speed window should not be negative, got %v", window)
+	}
+	return &SpeedEstimator{
+		calibration: calibration,
+		window:      window,
+		history:     map[int][]sample{},
+	}, nil
+}
+
+// Update processes the tracks of the next frame and returns the measurements of the confirmed
+// tracks, ordered by track ID. Tracks which can not be projected are skipped and tracks which
+// are no longer present are forgotten.
+func (e *SpeedEstimator) Update(tracks []track.Track, timestamp time.Time) []Measurement {
+	measurements := []Measurement{}
+	present := make(map[int]bool, len(tracks))
+	for _, t := range tracks {
+		present[t.ID] = true
+		if t.State != track.Confirmed {
+			continue
+		}
+		position, err := e.calibration.FootPoint(t.BoundingBox)
+		if err != nil {
+			continue
+		}
+		size, err := e.calibration.Size(t.BoundingBox)
+		if err != nil {
+			continue
+		}
+
+		history := append(e.history[t.ID], sample{timestamp: timestamp, position: position})
+		drop := 0
+		for drop < len(history)-2 && timestamp.Sub(history[drop+1].timestamp) >= e.window {
+			drop++
+		}
+		history = history[drop:]
+		e.history[t.ID] = history
+
+		speed := 0.0
+		if elapsed := timestamp.Sub(history[0].timestamp).Seconds(); elapsed > 0 {
+			speed = distance(history[0].position, position) / elapsed
+		}
+		measurements = append(measurements, Measurement{
+			TrackID:   t.ID,
+			ClassName: t.Detection.ClassName,
+			Position:  position,
+			Size:      size,
+			Speed:     speed,
+		})
+	}
+
+	for id := range e.history {
+		if !present[id] {
+			delete(e.history, id)
+		}
+	}
+	sort.Slice(measurements, func(i, j int) bool {
+		return measurements[i].TrackID < measurements[j].TrackID
+	})
+	return measurements
+}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
@@ -0,0 +1,142 @@
+package calibration
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/detect"
+	"github.com/wimspaargaren/yolov5/track"
+)
+
+type CalibrationTestSuite struct {
+	suite.Suite
+}
+
+func TestCalibrationTestSuite(t *testing.T) {
+	suite.Run(t, new(CalibrationTestSuite))
+}
+
+// perspective a homography with a perspective component, used to generate point pairs.
+var perspective = Homography{0.02, 0.005, -3, 0.001, 0.04, -2, 0.0001, 0.0004, 1}
+
+// pairs projects given pixels with the perspective homography.
+func (s *CalibrationTestSuite) pairs(pixels ...Point) []PointPair {
+	pairs := []PointPair{}
+	for _, p := range pixels {
+		world, err := perspective.Project(p)
+		s.Require().NoError(err)
+		pairs = append(pairs, PointPair{Pixel: p, World: world})
+	}
+	return pairs
+}
+
+func (s *CalibrationTestSuite) TestNewHomography() {
+	h, err := NewHomography(s.pairs(Point{0, 0}, Point{640, 0}, Point{640, 480}, Point{0, 480}))
+	s.Require().NoError(err)
+	for i := range perspective {
+		s.InDelta(perspective[i], h[i], 1e-9)
+	}
+
+	// Additional pairs are fitted in the least squares sense
+	pairs := s.pairs(Point{0, 0}, Point{640, 0}, Point{640, 480}, Point{0, 480}, Point{320, 240}, Point{100, 400})
+	pairs[4].World.X += 0.01
+	h, err = NewHomography(pairs)
+	s.Require().NoError(err)
+	s.Greater(h.ReprojectionError(pairs), 0.0)
+	s.Less(h.ReprojectionError(pairs), 0.01)
+}
+
+func (s *CalibrationTestSuite) TestNewHomographyInvalidPairs() {
+	_, err := NewHomography(s.pairs(Point{0, 0}, Point{640, 0}, Point{640, 480}))
+	s.Error(err)
+
+	_, err = NewHomography(s.pairs(Point{0, 0}, Point{100, 100}, Point{200, 200}, Point{300, 300}))
+	s.ErrorIs(err, ErrDegenerate)
+
+	_, err = NewHomography(s.pairs(Point{10, 10}, Point{10, 10}, Point{10, 10}, Point{10, 10}))
+	s.ErrorIs(err, ErrDegenerate)
+}
+
+func (s *CalibrationTestSuite) TestInverse() {
+	inverse, err := perspective.Inverse()
+	s.Require().NoError(err)
+	world, err := perspective.Project(Point{320, 240})
+	s.Require().NoError(err)
+	pixel, err := inverse.Project(world)
+	s.Require().NoError(err)
+	s.InDelta(320, pixel.X, 1e-9)
+	s.InDelta(240, pixel.Y, 1e-9)
+
+	_, err = Homography{}.Inverse()
+	s.ErrorIs(err, ErrDegenerate)
+}
+
+func (s *CalibrationTestSuite) TestFootPointAndSize() {
+	// Every pixel is 5 centimeters
+	calibration := NewFromHomography(Homography{0.05, 0, 0, 0, 0.05, 0, 0, 0, 1})
+	box := image.Rect(100, 40, 120, 80)
+
+	foot, err := calibration.FootPoint(box)
+	s.Require().NoError(err)
+	s.InDelta(5.5, foot.X, 1e-9)
+	s.InDelta(4, foot.Y, 1e-9)
+
+	size, err := calibration.Size(box)
+	s.Require().NoError(err)
+	s.InDelta(1, size.Width, 1e-9)
+	s.InDelta(2, size.Height, 1e-9)
+
+	_, err = calibration.Size(image.Rectangle{})
+	s.Error(err)
+}
+
+func (s *CalibrationTestSuite) TestSpeedEstimator() {
+	calibration := NewFromHomography(Homography{0.05, 0, 0, 0, 0.05, 0, 0, 0, 1})
+	estimator, err := NewSpeedEstimator(calibration, time.Second)
+	s.Require().NoError(err)
+
+	moving := func(x int) track.Track {
+		return track.Track{
+			ID:          1,
+			State:       track.Confirmed,
+			Detection:   detect.ObjectDetection{ClassName: "car"},
+			BoundingBox: image.Rect(x, 40, x+20, 80),
+		}
+	}
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	measurements := estimator.Update([]track.Track{moving(0)}, start)
+	s.Require().Len(measurements, 1)
+	s.Equal(0.0, measurements[0].Speed)
+
+	// The car moves 10 pixels, half a meter, every 100 milliseconds
+	for frame := 1; frame <= 20; frame++ {
+		measurements = estimator.Update([]track.Track{moving(10 * frame)}, start.Add(time.Duration(frame)*100*time.Millisecond))
+	}
+	s.Require().Len(measurements, 1)
+	s.Equal(1, measurements[0].TrackID)
+	s.Equal("car", measurements[0].ClassName)
+	s.InDelta(5, measurements[0].Speed, 1e-9)
+
+	// Tentative tracks are not measured and absent tracks are forgotten
+	tentative := moving(0)
+	tentative.State = track.Tentative
+	s.Empty(estimator.Update([]track.Track{tentative}, start.Add(3*time.Second)))
+	s.Empty(estimator.Update([]track.Track{}, start.Add(4*time.Second)))
+	measurements = estimator.Update([]track.Track{moving(500)}, start.Add(5*time.Second))
+	s.Require().Len(measurements, 1)
+	s.Equal(0.0, measurements[0].Speed)
+
+	_, err = NewSpeedEstimator(calibration, -time.Second)
+	s.Error(err)
+}
+
+func (s *CalibrationTestSuite) TestBirdsEyeView() {
+	view := BirdsEyeView{Min: Point{-5, 0}, Max: Point{5, 20}, Scale: 10}
+	s.Equal(image.Pt(100, 200), view.Size())
+	s.Equal(image.Pt(0, 0), view.ToPixel(Point{-5, 0}))
+	s.Equal(image.Pt(50, 125), view.ToPixel(Point{0, 12.5}))
+}
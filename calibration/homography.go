@@ -0,0 +1,220 @@
+// Package calibration maps pixel positions onto the ground plane using a homography, so the
+// position, size and speed of detected objects can be expressed in meters.
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrDegenerate is returned when the point pairs do not determine a homography, for example
+// when three or more of the points are collinear.
+var ErrDegenerate = errors.New("degenerate point pairs")
+
+// Point a point in pixels or in world coordinates.
+type Point struct {
+	X, Y float64
+}
+
+// PointPair maps a pixel onto its position on the ground plane, in meters.
+type PointPair struct {
+	Pixel Point
+	World Point
+}
+
+// Homography a projective transformation between two planes, stored as row-major 3x3 matrix.
+type Homography [9]float64
+
+// NewHomography estimates the homography which maps the pixels of given pairs onto their world
+// positions. At least four pairs are required, more pairs are fitted in the least squares sense.
+func NewHomography(pairs []PointPair) (Homography, error) {
+	if len(pairs) < 4 {
+		return Homography{}, fmt.Errorf("at least 4 point pairs are required, got %d", len(pairs))
+	}
+
+	// Normalise both point sets for numerical stability
+	pixels, worlds := make([]Point, len(pairs)), make([]Point, len(pairs))
+	for i, pair := range pairs {
+		pixels[i], worlds[i] = pair.Pixel, pair.World
+	}
+	pixelNorm, err := normalisation(pixels)
+	if err != nil {
+		return Homography{}, err
+	}
+	worldNorm, err := normalisation(worlds)
+	if err != nil {
+		return Homography{}, err
+	}
+
+	// Solve A h = b for the first 8 elements of the homography, with the last element fixed at 1
+	ata := [8][9]float64{}
+	for _, pair := range pairs {
+		p, w := pixelNorm.apply(pair.Pixel), worldNorm.apply(pair.World)
+		rows := [2][9]float64{
+			{p.X, p.Y, 1, 0, 0, 0, -p.X * w.X, -p.Y * w.X, w.X},
+			{0, 0, 0, p.X, p.Y, 1, -p.X * w.Y, -p.Y * w.Y, w.Y},
+		}
+		for _, row := range rows {
+			for i := 0; i < 8; i++ {
+				for j := 0; j < 9; j++ {
+					ata[i][j] += row[i] * row[j]
+				}
+			}
+		}
+	}
+	h, err := solve(ata)
+	if err != nil {
+		return Homography{}, err
+	}
+
+	normalised := Homography{h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7], 1}
+	worldDenorm, err := worldNorm.Inverse()
+	if err != nil {
+		return Homography{}, err
+	}
+	result := worldDenorm.mul(normalised).mul(pixelNorm)
+	if result[8] == 0 {
+		return Homography{}, ErrDegenerate
+	}
+	for i := range result {
+		result[i] /= result[8]
+	}
+
+	for _, pair := range pairs {
+		if _, err := result.Project(pair.Pixel); err != nil {
+			return Homography{}, err
+		}
+	}
+	return result, nil
+}
+
+// Project maps given point using the homography, an error is returned for points which are
+// mapped onto infinity, such as points on the horizon.
+func (h Homography) Project(p Point) (Point, error) {
+	w := h[6]*p.X + h[7]*p.Y + h[8]
+	if math.Abs(w) < 1e-12 {
+		return Point{}, fmt.Errorf("point %v is projected onto infinity", p)
+	}
+	return Point{
+		X: (h[0]*p.X + h[1]*p.Y + h[2]) / w,
+		Y: (h[3]*p.X + h[4]*p.Y + h[5]) / w,
+	}, nil
+}
+
+// Inverse returns the homography mapping in the opposite direction.
+func (h Homography) Inverse() (Homography, error) {
+	det := h[0]*(h[4]*h[8]-h[5]*h[7]) - h[1]*(h[3]*h[8]-h[5]*h[6]) + h[2]*(h[3]*h[7]-h[4]*h[6])
+	if math.Abs(det) < 1e-12 {
+		return Homography{}, ErrDegenerate
+	}
+	return Homography{
+		(h[4]*h[8] - h[5]*h[7]) / det,
+		(h[2]*h[7] - h[1]*h[8]) / det,
+		(h[1]*h[5] - h[2]*h[4]) / det,
+		(h[5]*h[6] - h[3]*h[8]) / det,
+		(h[0]*h[8] - h[2]*h[6]) / det,
+		(h[2]*h[3] - h[0]*h[5]) / det,
+		(h[3]*h[7] - h[4]*h[6]) / det,
+		(h[1]*h[6] - h[0]*h[7]) / det,
+		(h[0]*h[4] - h[1]*h[3]) / det,
+	}, nil
+}
+
+// ReprojectionError returns the root mean square distance between the projected pixels and
+// the world positions of given pairs, in meters.
+func (h Homography) ReprojectionError(pairs []PointPair) float64 {
+	if len(pairs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, pair := range pairs {
+		p, err := h.Project(pair.Pixel)
+		if err != nil {
+			return math.Inf(1)
+		}
+		sum += square(p.X-pair.World.X) + square(p.Y-pair.World.Y)
+	}
+	return math.Sqrt(sum / float64(len(pairs)))
+}
+
+// apply maps given point using an affine homography, such as a normalisation.
+func (h Homography) apply(p Point) Point {
+	return Point{
+		X: h[0]*p.X + h[1]*p.Y + h[2],
+		Y: h[3]*p.X + h[4]*p.Y + h[5],
+	}
+}
+
+func (h Homography) mul(o Homography) Homography {
+	res := Homography{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				res[i*3+j] += h[i*3+k] * o[k*3+j]
+			}
+		}
+	}
+	return res
+}
+
+// normalisation returns the similarity transform which moves the centroid of the points to the
+// origin and scales them to an average distance of sqrt(2) from it.
+func normalisation(points []Point) (Homography, error) {
+	cx, cy := 0.0, 0.0
+	for _, p := range points {
+		cx += p.X
+		cy += p.Y
+	}
+	cx /= float64(len(points))
+	cy /= float64(len(points))
+
+	distance := 0.0
+	for _, p := range points {
+		distance += math.Hypot(p.X-cx, p.Y-cy)
+	}
+	distance /= float64(len(points))
+	if distance == 0 {
+		return Homography{}, ErrDegenerate
+	}
+
+	s := math.Sqrt2 / distance
+	return Homography{s, 0, -s * cx, 0, s, -s * cy, 0, 0, 1}, nil
+}
+
+// solve solves the augmented 8x9 system using Gaussian elimination with partial pivoting.
+func solve(a [8][9]float64) ([8]float64, error) {
+	const n = 8
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-10 {
+			return [8]float64{}, ErrDegenerate
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for j := col; j <= n; j++ {
+				a[row][j] -= f * a[col][j]
+			}
+		}
+	}
+
+	x := [8]float64{}
+	for row := n - 1; row >= 0; row-- {
+		sum := a[row][n]
+		for j := row + 1; j < n; j++ {
+			sum -= a[row][j] * x[j]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
+
+func square(v float64) float64 {
+	return v * v
+}
@@ -0,0 +1,135 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// CropClassifier classifies the crops of the detections of a cascade.
+type CropClassifier interface {
+	Classify(gocv.Mat) (Classification, error)
+	Close() error
+}
+
+// CascadeConfig can be used to configure a cascade.
+type CascadeConfig struct {
+	// Classes contains the class names of the detections which should be classified, all detections are
+	// classified when no classes are given
+	Classes map[string]bool
+	// Padding enlarges the crop of a detection on every side, relative to the size of its bounding box
+	Padding float32
+	// MinScore is the minimum score of a classification before it is attached to the detection
+	MinScore float32
+}
+
+// Cascade runs a detector followed by a classifier on the crops of the detections,
+// the classification is attached to the detection as sub label.
+// The cascade is not safe for concurrent use.
+type Cascade struct {
+	detector   Net
+	classifier CropClassifier
+	config     CascadeConfig
+}
+
+// NewCascade creates a cascade of given detector and classifier.
+func NewCascade(detector Net, classifier CropClassifier, config CascadeConfig) (*Cascade, error) {
+	if detector == nil || classifier == nil {
+		return nil, fmt.Errorf("cascade requires both a detector and a classifier")
+	}
+	if config.Padding < 0 {
+		return nil, fmt.Errorf("cascade padding should not be negative, got %f", config.Padding)
+	}
+	return &Cascade{
+		detector:   detector,
+		classifier: classifier,
+		config:     config,
+	}, nil
+}
+
+// Close closes the detector and the classifier.
+func (c *Cascade) Close() error {
+	return errors.Join(c.detector.Close(), c.classifier.Close())
+}
+
+// GetDetections retrieve predicted and classified detections from given matrix.
+func (c *Cascade) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return c.Detect(frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects, but filter out a given list of coco name ids.
+func (c *Cascade) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
+	return c.Detect(frame, WithClassFilter(classIDsFilter))
+}
+
+// Detect retrieves predicted and classified detections from given matrix.
+func (c *Cascade) Detect(frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	return c.DetectContext(context.Background(), frame, opts...)
+}
+
+// DetectContext retrieves predicted and classified detections from given matrix.
+func (c *Cascade) DetectContext(ctx context.Context, frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	result, err := c.DetectResult(ctx, frame, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Detections, nil
+}
+
+// DetectResult retrieves the detection result of the detector and classifies the crops of the
+// detections of the configured classes. The time spent on classification is added to the
+// postprocess duration of the result.
+func (c *Cascade) DetectResult(ctx context.Context, frame gocv.Mat, opts ...DetectOption) (*DetectionResult, error) {
+	result, err := c.detector.DetectResult(ctx, frame, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	bounds := image.Rectangle{Max: result.FrameSize}
+	for i := range result.Detections {
+		detection := &result.Detections[i]
+		if len(c.config.Classes) > 0 && !c.config.Classes[detection.ClassName] {
+			continue
+		}
+		crop := padBox(detection.BoundingBox, c.config.Padding).Intersect(bounds)
+		if crop.Empty() {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("classification cancelled: %w", err)
+		}
+
+		classification, err := c.classify(frame, crop)
+		if err != nil {
+			return nil, fmt.Errorf("unable to classify %s detection at %v: %w", detection.ClassName, detection.BoundingBox, err)
+		}
+		if classification.Score < c.config.MinScore {
+			continue
+		}
+		detection.SubLabel = classification.ClassName
+		detection.SubLabelConfidence = classification.Score
+	}
+	result.Postprocess += time.Since(start)
+	return result, nil
+}
+
+// classify classifies the given region of the frame.
+func (c *Cascade) classify(frame gocv.Mat, crop image.Rectangle) (classification Classification, err error) {
+	defer recoverPanic("classification", &err)
+	region := frame.Region(crop)
+	// nolint: errcheck
+	defer region.Close()
+	return c.classifier.Classify(region)
+}
+
+// padBox enlarges given box on every side, relative to its size.
+func padBox(box image.Rectangle, padding float32) image.Rectangle {
+	dx := int(float32(box.Dx())*padding + 0.5)
+	dy := int(float32(box.Dy())*padding + 0.5)
+	return image.Rect(box.Min.X-dx, box.Min.Y-dy, box.Max.X+dx, box.Max.Y+dy)
+}
@@ -0,0 +1,109 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+)
+
+// staticClassifier a crop classifier which always returns the same classification.
+type staticClassifier struct {
+	classification Classification
+	closeErr       error
+}
+
+func (c *staticClassifier) Classify(gocv.Mat) (Classification, error) {
+	return c.classification, nil
+}
+
+func (c *staticClassifier) Close() error {
+	return c.closeErr
+}
+
+type CascadeTestSuite struct {
+	suite.Suite
+}
+
+func TestCascadeTestSuite(t *testing.T) {
+	suite.Run(t, new(CascadeTestSuite))
+}
+
+func (s *CascadeTestSuite) TestCorrectImplementation() {
+	var _ Net = &Cascade{}
+	var _ CropClassifier = &Classifier{}
+}
+
+func (s *CascadeTestSuite) TestNewCascadeInvalidConfig() {
+	_, err := NewCascade(nil, &staticClassifier{}, CascadeConfig{})
+	s.Error(err)
+	_, err = NewCascade(&staticNet{}, nil, CascadeConfig{})
+	s.Error(err)
+	_, err = NewCascade(&staticNet{}, &staticClassifier{}, CascadeConfig{Padding: -0.1})
+	s.Error(err)
+}
+
+func (s *CascadeTestSuite) TestPadBox() {
+	tests := []struct {
+		Name     string
+		Box      image.Rectangle
+		Padding  float32
+		Expected image.Rectangle
+	}{
+		{
+			Name:     "No padding",
+			Box:      image.Rect(10, 10, 30, 50),
+			Expected: image.Rect(10, 10, 30, 50),
+		},
+		{
+			Name:     "Padding relative to box size",
+			Box:      image.Rect(10, 10, 30, 50),
+			Padding:  0.1,
+			Expected: image.Rect(8, 6, 32, 54),
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Expected, padBox(test.Box, test.Padding))
+		})
+	}
+}
+
+func (s *CascadeTestSuite) TestDetectResultSkipsUnconfiguredClasses() {
+	detections := []ObjectDetection{
+		{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+	}
+	detector := &staticNet{result: &DetectionResult{
+		FrameSize:  image.Pt(640, 480),
+		Detections: detections,
+	}}
+	cascade, err := NewCascade(detector, &staticClassifier{
+		classification: Classification{ClassName: "sedan", Score: 0.9},
+	}, CascadeConfig{Classes: map[string]bool{"car": true}})
+	s.Require().NoError(err)
+
+	result, err := cascade.DetectResult(context.Background(), gocv.Mat{})
+	s.Require().NoError(err)
+	s.Equal(detections, result.Detections)
+	s.Empty(result.Detections[0].SubLabel)
+}
+
+func (s *CascadeTestSuite) TestDetectResultDetectorError() {
+	detectorErr := errors.New("forward failed")
+	cascade, err := NewCascade(&staticNet{err: detectorErr}, &staticClassifier{}, CascadeConfig{})
+	s.Require().NoError(err)
+
+	_, err = cascade.Detect(gocv.Mat{})
+	s.ErrorIs(err, detectorErr)
+}
+
+func (s *CascadeTestSuite) TestClose() {
+	closeErr := errors.New("close failed")
+	cascade, err := NewCascade(&staticNet{}, &staticClassifier{closeErr: closeErr}, CascadeConfig{})
+	s.Require().NoError(err)
+	s.ErrorIs(cascade.Close(), closeErr)
+}
@@ -0,0 +1,211 @@
+package yolov5
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/gocvnet"
+	"github.com/wimspaargaren/yolov5/labels"
+)
+
+// Default constants for initialising the classifier.
+const (
+	DefaultClassifierInputWidth  = 224
+	DefaultClassifierInputHeight = 224
+)
+
+// ClassifierConfig can be used to customise the settings of the neural network used for classification.
+type ClassifierConfig struct {
+	// InputWidth & InputHeight are used to determine the input size of the image for the network
+	InputWidth  int
+	InputHeight int
+	// Mean & Std are used to normalise every RGB channel, after the pixel values are scaled to [0, 1].
+	// A zero standard deviation disables the normalisation.
+	Mean [3]float32
+	Std  [3]float32
+	// Softmax should be enabled for networks which output logits instead of probabilities
+	Softmax bool
+
+	// Type on which the network will be executed
+	NetTargetType  gocv.NetTargetType
+	NetBackendType gocv.NetBackendType
+
+	// NewNet function can be used to inject a custom neural net
+	NewNet func(modelPath string) ml.NeuralNet
+}
+
+// validate ensures that the basic fields of the config are set
+func (c *ClassifierConfig) validate() {
+	if c.NewNet == nil {
+		c.NewNet = initializeNet
+	}
+	if c.InputWidth == 0 {
+		c.InputWidth = DefaultClassifierInputWidth
+	}
+	if c.InputHeight == 0 {
+		c.InputHeight = DefaultClassifierInputHeight
+	}
+}
+
+// DefaultClassifierConfig used to create a classifier for a model trained on ImageNet normalised images.
+func DefaultClassifierConfig() ClassifierConfig {
+	return ClassifierConfig{
+		InputWidth:     DefaultClassifierInputWidth,
+		InputHeight:    DefaultClassifierInputHeight,
+		Mean:           [3]float32{0.485, 0.456, 0.406},
+		Std:            [3]float32{0.229, 0.224, 0.225},
+		Softmax:        true,
+		NetTargetType:  gocv.NetTargetCPU,
+		NetBackendType: gocv.NetBackendDefault,
+		NewNet:         initializeNet,
+	}
+}
+
+// Classification represents the predicted class of an image.
+type Classification struct {
+	ClassID   int
+	ClassName string
+	Score     float32
+}
+
+// Classifier classifies (crops of) images using an ONNX image classification model.
+// The classifier is not safe for concurrent use.
+type Classifier struct {
+	net    ml.NeuralNet
+	labels []string
+	config ClassifierConfig
+}
+
+// NewClassifier creates a classifier for the model and labels of the given paths.
+func NewClassifier(modelPath, labelsPath string, config ClassifierConfig) (*Classifier, error) {
+	config.validate()
+
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, modelPath)
+	}
+
+	names, err := labels.Load(labelsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	net := config.NewNet(modelPath)
+	err = setNetTargetTypes(net, Config{
+		NetTargetType:  config.NetTargetType,
+		NetBackendType: config.NetBackendType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Classifier{
+		net:    net,
+		labels: names,
+		config: config,
+	}, nil
+}
+
+// Close closes the classifier.
+func (c *Classifier) Close() error {
+	return c.net.Close()
+}
+
+// Classify predicts the class of given image.
+func (c *Classifier) Classify(img gocv.Mat) (Classification, error) {
+	img, release, err := normaliseFrame(img)
+	if err != nil {
+		return Classification{}, err
+	}
+	defer release()
+
+	blob := gocv.BlobFromImage(img, 1.0/255.0, image.Pt(c.config.InputWidth, c.config.InputHeight), gocv.NewScalar(0, 0, 0, 0), true, false)
+	// nolint: errcheck
+	defer blob.Close()
+
+	input := &gocvnet.MatTensor{Mat: blob}
+	normalise(input.Data(), c.config.Mean, c.config.Std)
+
+	outputs, err := c.net.Forward(input)
+	if err != nil {
+		return Classification{}, err
+	}
+	for i := 0; i < len(outputs); i++ {
+		if closer, ok := outputs[i].(io.Closer); ok {
+			// nolint: errcheck
+			defer closer.Close()
+		}
+	}
+	return c.processOutputs(outputs)
+}
+
+// processOutputs selects the class with the highest score from the outputs.
+func (c *Classifier) processOutputs(outputs []ml.Tensor) (Classification, error) {
+	if len(outputs) == 0 {
+		return Classification{}, fmt.Errorf("classifier did not produce any outputs")
+	}
+
+	scores := outputs[0].Data()
+	if len(scores) == 0 {
+		return Classification{}, fmt.Errorf("classifier produced an empty output of shape %v", outputs[0].Shape())
+	}
+	if len(scores) > len(c.labels) {
+		return Classification{}, fmt.Errorf("%w: classifier predicts %d classes, but only %d labels are known", ErrLabelsMismatch, len(scores), len(c.labels))
+	}
+	if c.config.Softmax {
+		scores = softmax(scores)
+	}
+
+	best := 0
+	for i, score := range scores {
+		if score > scores[best] {
+			best = i
+		}
+	}
+	return Classification{
+		ClassID:   best,
+		ClassName: c.labels[best],
+		Score:     scores[best],
+	}, nil
+}
+
+// normalise normalises the channels of a blob shaped as [1, 3, height, width] in place.
+func normalise(data []float32, mean, std [3]float32) {
+	channelSize := len(data) / 3
+	for c := 0; c < 3; c++ {
+		if std[c] == 0 {
+			continue
+		}
+		channel := data[c*channelSize : (c+1)*channelSize]
+		for i := range channel {
+			channel[i] = (channel[i] - mean[c]) / std[c]
+		}
+	}
+}
+
+// softmax converts given logits into probabilities.
+func softmax(logits []float32) []float32 {
+	highest := logits[0]
+	for _, logit := range logits {
+		if logit > highest {
+			highest = logit
+		}
+	}
+
+	sum := float64(0)
+	probabilities := make([]float32, len(logits))
+	for i, logit := range logits {
+		exp := math.Exp(float64(logit - highest))
+		probabilities[i] = float32(exp)
+		sum += exp
+	}
+	for i := range probabilities {
+		probabilities[i] = float32(float64(probabilities[i]) / sum)
+	}
+	return probabilities
+}
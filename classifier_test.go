@@ -0,0 +1,111 @@
+package yolov5
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/mocks"
+)
+
+type ClassifierTestSuite struct {
+	suite.Suite
+}
+
+func TestClassifierTestSuite(t *testing.T) {
+	suite.Run(t, new(ClassifierTestSuite))
+}
+
+func (s *ClassifierTestSuite) TestNewClassifierNonExistentModel() {
+	_, err := NewClassifier("data/classifier/notexistent", "data/yolov5/coco.names", DefaultClassifierConfig())
+	s.ErrorIs(err, ErrModelNotFound)
+}
+
+func (s *ClassifierTestSuite) TestNewClassifierCustomNet() {
+	controller := gomock.NewController(s.T())
+	neuralNet := mocks.NewMockNeuralNet(controller)
+
+	classifier, err := NewClassifier("data/yolov5/yolov5s.onnx", "data/yolov5/coco.names", ClassifierConfig{
+		NewNet: func(string) ml.NeuralNet {
+			return neuralNet
+		},
+	})
+	s.Require().NoError(err)
+	s.Equal(DefaultClassifierInputWidth, classifier.config.InputWidth)
+	s.Equal(DefaultClassifierInputHeight, classifier.config.InputHeight)
+
+	neuralNet.EXPECT().Close().Return(nil).Times(1)
+	s.NoError(classifier.Close())
+}
+
+func (s *ClassifierTestSuite) TestProcessOutputs() {
+	tests := []struct {
+		Name     string
+		Softmax  bool
+		Scores   []float32
+		Expected Classification
+		Error    bool
+	}{
+		{
+			Name:     "Probabilities",
+			Scores:   []float32{0.1, 0.7, 0.2},
+			Expected: Classification{ClassID: 1, ClassName: "sedan", Score: 0.7},
+		},
+		{
+			Name:     "Logits",
+			Softmax:  true,
+			Scores:   []float32{0, 0, 0, 0},
+			Expected: Classification{ClassID: 0, ClassName: "hatchback", Score: 0.25},
+		},
+		{
+			Name:   "More classes than labels",
+			Scores: []float32{0.1, 0.1, 0.1, 0.1, 0.6},
+			Error:  true,
+		},
+		{
+			Name:   "Empty output",
+			Scores: []float32{},
+			Error:  true,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			classifier := &Classifier{
+				labels: []string{"hatchback", "sedan", "suv", "van"},
+				config: ClassifierConfig{Softmax: test.Softmax},
+			}
+			output, err := ml.NewTensor([]int{1, len(test.Scores)}, test.Scores)
+			s.Require().NoError(err)
+
+			classification, err := classifier.processOutputs([]ml.Tensor{output})
+			if test.Error {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Expected, classification)
+		})
+	}
+}
+
+func (s *ClassifierTestSuite) TestProcessOutputsNoOutputs() {
+	classifier := &Classifier{labels: []string{"sedan"}}
+	_, err := classifier.processOutputs([]ml.Tensor{})
+	s.Error(err)
+}
+
+func (s *ClassifierTestSuite) TestSoftmax() {
+	probabilities := softmax([]float32{1, 2, 3})
+	s.InDelta(0.0900, probabilities[0], 0.0001)
+	s.InDelta(0.2447, probabilities[1], 0.0001)
+	s.InDelta(0.6652, probabilities[2], 0.0001)
+}
+
+func (s *ClassifierTestSuite) TestNormalise() {
+	data := []float32{0.5, 0.5, 0.5, 0.5, 1, 1}
+	normalise(data, [3]float32{0.5, 0, 0.5}, [3]float32{0.5, 0, 0.25})
+	s.Equal([]float32{0, 0, 0.5, 0.5, 2, 2}, data)
+}
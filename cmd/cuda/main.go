@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -11,6 +12,7 @@ import (
 	"gocv.io/x/gocv"
 
 	"github.com/wimspaargaren/yolov5"
+	"github.com/wimspaargaren/yolov5/pipeline"
 )
 
 var (
@@ -52,27 +54,41 @@ func main() {
 		}
 	}()
 
-	// Render example image at 50 frames a second
-	ticker := time.NewTicker(time.Second / 50)
-	go func() {
-		for {
-			<-ticker.C
-			frame := orgFrame.Clone()
-			detections, err := yolonet.GetDetections(frame)
-			if err != nil {
-				err = fmt.Errorf("%w %w", err, frame.Close())
-				log.WithError(err).Fatal("unable to retrieve predictions")
-				continue
+	// Render the example image as fast as the GPU allows, frames are dropped when it can't keep up
+	p, err := pipeline.New(pipeline.Config{
+		Source:     &repeatSource{frame: orgFrame},
+		Detector:   yolonet,
+		DropPolicy: pipeline.DropOldest,
+		Sinks: []pipeline.Sink{pipeline.SinkFunc(func(_ context.Context, result pipeline.Result) error {
+			if result.Err != nil {
+				return fmt.Errorf("unable to retrieve predictions: %w", result.Err)
 			}
+			yolov5.DrawDetections(&result.Frame.Mat, result.Detections)
+			window.IMShow(result.Frame.Mat)
+			window.WaitKey(1)
+			return nil
+		})},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("unable to create pipeline")
+	}
+	err = p.Run(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("pipeline stopped")
+	}
+}
 
-			yolov5.DrawDetections(&frame, detections)
+// repeatSource provides a copy of the same frame over and over again.
+type repeatSource struct {
+	frame gocv.Mat
+	index int
+}
 
-			window.IMShow(frame)
-			err = frame.Close()
-			if err != nil {
-				log.WithError(err).Error("unable to close frame")
-			}
-		}
-	}()
-	window.WaitKey(10000000000)
+func (s *repeatSource) Read(ctx context.Context) (pipeline.Frame, error) {
+	if err := ctx.Err(); err != nil {
+		return pipeline.Frame{}, err
+	}
+	frame := pipeline.Frame{Index: s.index, Timestamp: time.Now(), Mat: s.frame.Clone()}
+	s.index++
+	return frame, nil
 }
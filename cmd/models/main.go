@@ -0,0 +1,182 @@
+// Package main provides a command for managing the local cache of yolov5 models.
+//
+// Usage:
+//
+//	models [-cache dir] pull [-pin] [-mirror url]... manifest.json...
+//	models [-cache dir] list
+//	models [-cache dir] verify [name]...
+//	models [-cache dir] rm name...
+//
+// Mirrors can also be configured using the YOLOV5_MODEL_MIRRORS environment variable,
+// which contains a comma separated list of base URLs.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wimspaargaren/yolov5/internal/modelstore"
+	"github.com/wimspaargaren/yolov5/manifest"
+)
+
+// stringsFlag a flag which can be provided multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	cacheDir := flag.String("cache", defaultCacheDir(), "specify the directory in which the models are stored")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	store := modelstore.New(*cacheDir)
+	store.Log = os.Stdout
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "pull":
+		err = pull(ctx, store, args)
+	case "list":
+		err = list(store)
+	case "verify":
+		err = verify(store, args)
+	case "rm":
+		err = remove(store, args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("unable to manage models")
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  models [-cache dir] pull [-pin] [-mirror url]... manifest.json...
+  models [-cache dir] list
+  models [-cache dir] verify [name]...
+  models [-cache dir] rm name...
+
+`)
+	flag.PrintDefaults()
+}
+
+// defaultCacheDir returns the user cache directory, falling back to the working directory.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "models"
+	}
+	return filepath.Join(dir, "yolov5")
+}
+
+func pull(ctx context.Context, store *modelstore.Store, args []string) error {
+	flags := flag.NewFlagSet("pull", flag.ExitOnError)
+	pin := flags.Bool("pin", false, "trust and pin the checksums of files which are not pinned in the manifest")
+	var mirrors stringsFlag
+	flags.Var(&mirrors, "mirror", "specify a mirror base URL, can be provided multiple times")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("no manifests provided")
+	}
+
+	if env := os.Getenv("YOLOV5_MODEL_MIRRORS"); env != "" {
+		mirrors = append(mirrors, strings.Split(env, ",")...)
+	}
+	store.Mirrors = mirrors
+
+	for _, path := range flags.Args() {
+		m, err := manifest.Load(path)
+		if err != nil {
+			return err
+		}
+		cached, err := store.Pull(ctx, m, *pin)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("pulled %s into %s\n", cached.ID(), filepath.Dir(cached.ModelPath()))
+	}
+	return nil
+}
+
+func list(store *modelstore.Store) error {
+	manifests, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tMODEL\tLABELS")
+	for _, m := range manifests {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Name, m.Version, m.ModelPath(), m.LabelsPath())
+	}
+	return w.Flush()
+}
+
+func verify(store *modelstore.Store, names []string) error {
+	if len(names) == 0 {
+		manifests, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, m := range manifests {
+			names = append(names, m.Name)
+		}
+	}
+
+	failed := 0
+	for _, name := range names {
+		err := store.Verify(name)
+		if err != nil {
+			failed++
+			fmt.Printf("%s: FAILED: %s\n", name, err)
+			continue
+		}
+		fmt.Printf("%s: OK\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d models failed verification", failed, len(names))
+	}
+	return nil
+}
+
+func remove(store *modelstore.Store, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no models provided")
+	}
+	for _, name := range names {
+		err := store.Remove(name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("removed %s\n", name)
+	}
+	return nil
+}
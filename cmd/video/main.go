@@ -0,0 +1,90 @@
+// Package main provides an example on how to run yolov5 on a video file and write an annotated copy.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/wimspaargaren/yolov5"
+	"github.com/wimspaargaren/yolov5/pipeline"
+)
+
+var (
+	yolov5Model   = path.Join(os.Getenv("GOPATH"), "src/github.com/wimspaargaren/yolov5/data/yolov5/yolov5s.onnx")
+	cocoNamesPath = path.Join(os.Getenv("GOPATH"), "src/github.com/wimspaargaren/yolov5/data/yolov5/coco.names")
+)
+
+// progressInterval is the amount of frames between progress reports.
+const progressInterval = 100
+
+func main() {
+	input := flag.String("i", "", "specify the path of the video")
+	output := flag.String("o", "", "specify the path of the annotated video, defaults to the input path with an _annotated suffix")
+	every := flag.Int("every", 1, "run the detection on every nth frame only")
+	logPath := flag.String("log", "", "specify the path of an optional JSON lines detection log")
+	codec := flag.String("codec", pipeline.DefaultCodec, "specify the four character code of the output codec")
+	flag.Parse()
+
+	if *input == "" {
+		log.Fatal("no input video specified")
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(*input, path.Ext(*input)) + "_annotated.mp4"
+	}
+
+	yolonet, err := yolov5.NewNet(yolov5Model, cocoNamesPath)
+	if err != nil {
+		log.WithError(err).Fatal("unable to create yolo net")
+	}
+
+	// Gracefully close the net when the program is done
+	defer func() {
+		err := yolonet.Close()
+		if err != nil {
+			log.WithError(err).Error("unable to gracefully close yolo net")
+		}
+	}()
+
+	config := pipeline.VideoProcessConfig{
+		Input:       *input,
+		Output:      *output,
+		Codec:       *codec,
+		DetectEvery: *every,
+		Progress: func(p pipeline.Progress) {
+			if p.Frames%progressInterval != 0 {
+				return
+			}
+			log.Infof("processed %d/%d frames (%.0f%%) at %.1f fps", p.Frames, p.Total, 100*p.Fraction(), p.FPS())
+		},
+	}
+	if *logPath != "" {
+		logFile, err := os.Create(*logPath)
+		if err != nil {
+			log.WithError(err).Fatal("unable to create detection log")
+		}
+		defer func() {
+			err := logFile.Close()
+			if err != nil {
+				log.WithError(err).Error("unable to close detection log")
+			}
+		}()
+		config.Log = logFile
+	}
+
+	// Stop processing on interrupt, the frames written so far are kept
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	metrics, err := pipeline.ProcessVideo(ctx, yolonet, config)
+	if err != nil {
+		log.WithError(err).Error("unable to process video")
+		return
+	}
+	log.Infof("wrote %s, %d frames, %v average detection time", *output, metrics.Sink.Count, metrics.Detect.Mean())
+}
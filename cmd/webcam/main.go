@@ -2,6 +2,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path"
 
@@ -9,6 +11,8 @@ import (
 	"gocv.io/x/gocv"
 
 	"github.com/wimspaargaren/yolov5"
+	"github.com/wimspaargaren/yolov5/pipeline"
+	"github.com/wimspaargaren/yolov5/smooth"
 )
 
 var (
@@ -30,42 +34,53 @@ func main() {
 		}
 	}()
 
-	videoCapture, err := gocv.OpenVideoCapture(0)
+	// Smooth the detections so boxes don't flicker when the confidence hovers around the threshold
+	filter, err := smooth.New(smooth.DefaultConfig())
 	if err != nil {
-		log.WithError(err).Fatal("unable to start video capture")
+		log.WithError(err).Fatal("unable to create temporal filter")
 	}
 
-	window := gocv.NewWindow("Result Window")
+	// Empty frames of a warming up camera are skipped, the camera is reconnected with backoff when reading fails
+	source, err := pipeline.NewCaptureDeviceSource(0)
+	if err != nil {
+		log.WithError(err).Fatal("unable to start video capture")
+	}
 	defer func() {
-		err := window.Close()
+		err := source.Close()
 		if err != nil {
-			log.WithError(err).Error("unable to close window")
+			log.WithError(err).Error("unable to close video capture")
 		}
 	}()
 
-	frame := gocv.NewMat()
+	window := gocv.NewWindow("Result Window")
 	defer func() {
-		err := frame.Close()
+		err := window.Close()
 		if err != nil {
-			log.WithError(err).Errorf("unable to close image")
+			log.WithError(err).Error("unable to close window")
 		}
 	}()
 
-	for {
-		if ok := videoCapture.Read(&frame); !ok {
-			log.Error("unable to read videostram")
-		}
-		if frame.Empty() {
-			continue
-		}
-		detections, err := yolonet.GetDetections(frame)
-		if err != nil {
-			log.WithError(err).Fatal("unable to retrieve predictions")
-		}
+	// Old frames are dropped when detection can't keep up with the camera
+	p, err := pipeline.New(pipeline.Config{
+		Source:     source,
+		Detector:   yolonet,
+		DropPolicy: pipeline.DropOldest,
+		Sinks: []pipeline.Sink{pipeline.SinkFunc(func(_ context.Context, result pipeline.Result) error {
+			if result.Err != nil {
+				return fmt.Errorf("unable to retrieve predictions: %w", result.Err)
+			}
 
-		yolov5.DrawDetections(&frame, detections)
-
-		window.IMShow(frame)
-		window.WaitKey(1)
+			yolov5.DrawDetections(&result.Frame.Mat, filter.Update(result.Detections))
+			window.IMShow(result.Frame.Mat)
+			window.WaitKey(1)
+			return nil
+		})},
+	})
+	if err != nil {
+		log.WithError(err).Fatal("unable to create pipeline")
+	}
+	err = p.Run(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("pipeline stopped")
 	}
 }
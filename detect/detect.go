@@ -0,0 +1,18 @@
+// Package detect contains the detections produced by a yolov5 net. It does not depend on gocv,
+// so packages which only process detections, such as trackers, build without OpenCV.
+package detect
+
+import (
+	"image"
+)
+
+// ObjectDetection represents information of an object detected by the neural net.
+type ObjectDetection struct {
+	ClassID     int
+	ClassName   string
+	BoundingBox image.Rectangle
+	Confidence  float32
+	// SubLabel & SubLabelConfidence contain the fine-grained classification of the object, when classified by a cascade
+	SubLabel           string
+	SubLabelConfidence float32
+}
@@ -0,0 +1,234 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5/internal/postprocess"
+)
+
+// DefaultEnsembleIoUThreshold is the intersection over union above which detections of
+// different models are considered to be the same object.
+const DefaultEnsembleIoUThreshold = 0.55
+
+// EnsembleMember a net which is part of an ensemble.
+type EnsembleMember struct {
+	Net Net
+	// Weight of the detections of the net during fusion, defaults to 1
+	Weight float32
+	// Labels maps the class names of the net onto the vocabulary of the ensemble. Class names
+	// which are not mapped are kept when they are part of the vocabulary and dropped otherwise.
+	Labels map[string]string
+}
+
+// EnsembleConfig can be used to configure an ensemble.
+type EnsembleConfig struct {
+	// Vocabulary contains the class names of the ensemble, the index of a name is used as class id
+	Vocabulary []string
+	// IoUThreshold is the intersection over union above which detections are fused
+	IoUThreshold float32
+	// ConfidenceThreshold is the minimum confidence of a fused detection
+	ConfidenceThreshold float32
+}
+
+// Ensemble runs several nets on the same frame concurrently and fuses their detections
+// using weighted box fusion.
+type Ensemble struct {
+	members []EnsembleMember
+	config  EnsembleConfig
+	classes map[string]int
+}
+
+// NewEnsemble creates an ensemble over the given members.
+func NewEnsemble(config EnsembleConfig, members ...EnsembleMember) (*Ensemble, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("ensemble should have at least one member")
+	}
+	if len(config.Vocabulary) == 0 {
+		return nil, fmt.Errorf("ensemble vocabulary should not be empty")
+	}
+	if config.IoUThreshold == 0 {
+		config.IoUThreshold = DefaultEnsembleIoUThreshold
+	}
+
+	classes := make(map[string]int, len(config.Vocabulary))
+	for i, name := range config.Vocabulary {
+		if _, ok := classes[name]; ok {
+			return nil, fmt.Errorf("ensemble vocabulary contains duplicate class name %q", name)
+		}
+		classes[name] = i
+	}
+
+	ensembleMembers := make([]EnsembleMember, len(members))
+	for i, member := range members {
+		if member.Net == nil {
+			return nil, fmt.Errorf("ensemble member %d has no net", i)
+		}
+		if member.Weight < 0 {
+			return nil, fmt.Errorf("ensemble member %d has negative weight %f", i, member.Weight)
+		}
+		if member.Weight == 0 {
+			member.Weight = 1
+		}
+		for from, to := range member.Labels {
+			if _, ok := classes[to]; !ok {
+				return nil, fmt.Errorf("ensemble member %d maps %q onto unknown class name %q", i, from, to)
+			}
+		}
+		ensembleMembers[i] = member
+	}
+
+	return &Ensemble{
+		members: ensembleMembers,
+		config:  config,
+		classes: classes,
+	}, nil
+}
+
+// Close closes all nets of the ensemble.
+func (e *Ensemble) Close() error {
+	errs := []error{}
+	for _, member := range e.members {
+		if err := member.Net.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetDetections retrieve the fused detections from given matrix.
+func (e *Ensemble) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return e.Detect(frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects, but filter out a given list of class names.
+func (e *Ensemble) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
+	return e.Detect(frame, WithClassFilter(classIDsFilter))
+}
+
+// Detect retrieves the fused detections from given matrix.
+func (e *Ensemble) Detect(frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	return e.DetectContext(context.Background(), frame, opts...)
+}
+
+// DetectContext retrieves the fused detections from given matrix.
+func (e *Ensemble) DetectContext(ctx context.Context, frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	result, err := e.DetectResult(ctx, frame, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Detections, nil
+}
+
+// DetectResult runs all nets of the ensemble concurrently and fuses their detections.
+// The class filter and the maximum amount of detections are applied to the fused detections,
+// the other options are passed on to the nets. The durations of the result are the ones of
+// the slowest net, the postprocess duration includes the time spent on fusion.
+func (e *Ensemble) DetectResult(ctx context.Context, frame gocv.Mat, opts ...DetectOption) (*DetectionResult, error) {
+	o := DetectOptions{
+		ConfidenceThreshold: e.config.ConfidenceThreshold,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	memberOpts := append(append([]DetectOption{}, opts...), WithClassFilter(nil), WithMaxDetections(0))
+
+	results := make([]*DetectionResult, len(e.members))
+	errs := make([]error, len(e.members))
+	wg := sync.WaitGroup{}
+	for i, member := range e.members {
+		wg.Add(1)
+		go func(i int, net Net) {
+			defer wg.Done()
+			results[i], errs[i] = net.DetectResult(ctx, frame, memberOpts...)
+		}(i, member.Net)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("ensemble member %d failed: %w", i, err)
+		}
+	}
+
+	start := time.Now()
+	detections := make([][]postprocess.Detection, len(results))
+	weights := make([]float32, len(results))
+	for i, result := range results {
+		detections[i] = e.mapDetections(e.members[i], result.Detections)
+		weights[i] = e.members[i].Weight
+	}
+	fused := postprocess.WeightedBoxFusion(detections, weights, e.config.IoUThreshold)
+
+	res := &DetectionResult{
+		Detections: []ObjectDetection{},
+		FrameSize:  results[0].FrameSize,
+		Transform:  results[0].Transform,
+		Model:      e.modelID(results),
+	}
+	for _, detection := range fused {
+		name := e.config.Vocabulary[detection.ClassID]
+		if detection.Confidence < o.ConfidenceThreshold || o.ClassIDsFilter[name] {
+			continue
+		}
+		if o.MaxDetections > 0 && len(res.Detections) == o.MaxDetections {
+			break
+		}
+		res.Detections = append(res.Detections, ObjectDetection{
+			ClassID:     detection.ClassID,
+			ClassName:   name,
+			BoundingBox: detection.BoundingBox,
+			Confidence:  detection.Confidence,
+		})
+	}
+
+	for _, result := range results {
+		res.Preprocess = maxDuration(res.Preprocess, result.Preprocess)
+		res.Forward = maxDuration(res.Forward, result.Forward)
+		res.Postprocess = maxDuration(res.Postprocess, result.Postprocess)
+	}
+	res.Postprocess += time.Since(start)
+	return res, nil
+}
+
+// mapDetections maps the detections of a member onto the vocabulary of the ensemble.
+func (e *Ensemble) mapDetections(member EnsembleMember, detections []ObjectDetection) []postprocess.Detection {
+	mapped := make([]postprocess.Detection, 0, len(detections))
+	for _, detection := range detections {
+		name := detection.ClassName
+		if to, ok := member.Labels[name]; ok {
+			name = to
+		}
+		classID, ok := e.classes[name]
+		if !ok {
+			continue
+		}
+		mapped = append(mapped, postprocess.Detection{
+			ClassID:     classID,
+			Confidence:  detection.Confidence,
+			BoundingBox: detection.BoundingBox,
+		})
+	}
+	return mapped
+}
+
+// modelID identifies the ensemble by the models of its members.
+func (e *Ensemble) modelID(results []*DetectionResult) string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Model)
+	}
+	return strings.Join(ids, "+")
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,181 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+)
+
+// staticNet a net which always returns the same result.
+type staticNet struct {
+	Net
+	result   *DetectionResult
+	err      error
+	options  DetectOptions
+	closeErr error
+}
+
+func (n *staticNet) DetectResult(_ context.Context, _ gocv.Mat, opts ...DetectOption) (*DetectionResult, error) {
+	for _, opt := range opts {
+		opt(&n.options)
+	}
+	if n.err != nil {
+		return nil, n.err
+	}
+	return n.result, nil
+}
+
+func (n *staticNet) Close() error {
+	return n.closeErr
+}
+
+type EnsembleTestSuite struct {
+	suite.Suite
+}
+
+func TestEnsembleTestSuite(t *testing.T) {
+	suite.Run(t, new(EnsembleTestSuite))
+}
+
+func (s *EnsembleTestSuite) TestCorrectImplementation() {
+	var _ Net = &Ensemble{}
+}
+
+func (s *EnsembleTestSuite) TestNewEnsembleInvalidConfig() {
+	tests := []struct {
+		Name    string
+		Config  EnsembleConfig
+		Members []EnsembleMember
+	}{
+		{
+			Name:   "No members",
+			Config: EnsembleConfig{Vocabulary: []string{"person"}},
+		},
+		{
+			Name:    "Empty vocabulary",
+			Members: []EnsembleMember{{Net: &staticNet{}}},
+		},
+		{
+			Name:    "Duplicate class name",
+			Config:  EnsembleConfig{Vocabulary: []string{"person", "person"}},
+			Members: []EnsembleMember{{Net: &staticNet{}}},
+		},
+		{
+			Name:    "Missing net",
+			Config:  EnsembleConfig{Vocabulary: []string{"person"}},
+			Members: []EnsembleMember{{}},
+		},
+		{
+			Name:    "Negative weight",
+			Config:  EnsembleConfig{Vocabulary: []string{"person"}},
+			Members: []EnsembleMember{{Net: &staticNet{}, Weight: -1}},
+		},
+		{
+			Name:    "Label mapped onto unknown class",
+			Config:  EnsembleConfig{Vocabulary: []string{"person"}},
+			Members: []EnsembleMember{{Net: &staticNet{}, Labels: map[string]string{"pedestrian": "human"}}},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := NewEnsemble(test.Config, test.Members...)
+			s.Error(err)
+		})
+	}
+}
+
+func (s *EnsembleTestSuite) TestDetectResult() {
+	general := &staticNet{result: &DetectionResult{
+		Model:     "yolov5s.onnx",
+		FrameSize: image.Pt(640, 480),
+		Forward:   10 * time.Millisecond,
+		Detections: []ObjectDetection{
+			{ClassID: 0, ClassName: "person", Confidence: 0.8, BoundingBox: image.Rect(0, 0, 20, 20)},
+			{ClassID: 2, ClassName: "car", Confidence: 0.9, BoundingBox: image.Rect(100, 100, 200, 200)},
+			{ClassID: 16, ClassName: "dog", Confidence: 0.7, BoundingBox: image.Rect(300, 300, 320, 320)},
+		},
+	}}
+	finetune := &staticNet{result: &DetectionResult{
+		Model:     "finetune.onnx",
+		FrameSize: image.Pt(640, 480),
+		Forward:   20 * time.Millisecond,
+		Detections: []ObjectDetection{
+			{ClassID: 0, ClassName: "pedestrian", Confidence: 0.8, BoundingBox: image.Rect(2, 2, 22, 22)},
+		},
+	}}
+
+	ensemble, err := NewEnsemble(EnsembleConfig{Vocabulary: []string{"person", "car"}},
+		EnsembleMember{Net: general},
+		EnsembleMember{Net: finetune, Labels: map[string]string{"pedestrian": "person"}},
+	)
+	s.Require().NoError(err)
+
+	result, err := ensemble.DetectResult(context.Background(), gocv.Mat{})
+	s.Require().NoError(err)
+	s.Equal("yolov5s.onnx+finetune.onnx", result.Model)
+	s.Equal(image.Pt(640, 480), result.FrameSize)
+	s.Equal(20*time.Millisecond, result.Forward)
+	s.Equal([]ObjectDetection{
+		// The car is only detected by one of the models, so its confidence is halved
+		{ClassID: 0, ClassName: "person", Confidence: 0.8, BoundingBox: image.Rect(1, 1, 21, 21)},
+		{ClassID: 1, ClassName: "car", Confidence: 0.45, BoundingBox: image.Rect(100, 100, 200, 200)},
+	}, result.Detections)
+}
+
+func (s *EnsembleTestSuite) TestDetectOptions() {
+	net := &staticNet{result: &DetectionResult{
+		Detections: []ObjectDetection{
+			{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+			{ClassID: 1, ClassName: "car", Confidence: 0.7, BoundingBox: image.Rect(100, 100, 200, 200)},
+			{ClassID: 0, ClassName: "person", Confidence: 0.3, BoundingBox: image.Rect(300, 300, 320, 320)},
+		},
+	}}
+	ensemble, err := NewEnsemble(EnsembleConfig{
+		Vocabulary:          []string{"person", "car"},
+		ConfidenceThreshold: 0.5,
+	}, EnsembleMember{Net: net})
+	s.Require().NoError(err)
+
+	detections, err := ensemble.Detect(gocv.Mat{},
+		WithClassFilter(map[string]bool{"car": true}),
+		WithMaxDetections(1),
+		WithNMSThreshold(0.3),
+	)
+	s.Require().NoError(err)
+	s.Equal([]ObjectDetection{
+		{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+	}, detections)
+
+	// Filtering and limiting is done after fusion, other options are passed on
+	s.Nil(net.options.ClassIDsFilter)
+	s.Equal(0, net.options.MaxDetections)
+	s.Equal(float32(0.3), net.options.NMSThreshold)
+}
+
+func (s *EnsembleTestSuite) TestDetectMemberError() {
+	memberErr := errors.New("forward failed")
+	ensemble, err := NewEnsemble(EnsembleConfig{Vocabulary: []string{"person"}},
+		EnsembleMember{Net: &staticNet{result: &DetectionResult{}}},
+		EnsembleMember{Net: &staticNet{err: memberErr}},
+	)
+	s.Require().NoError(err)
+
+	_, err = ensemble.Detect(gocv.Mat{})
+	s.ErrorIs(err, memberErr)
+}
+
+func (s *EnsembleTestSuite) TestClose() {
+	closeErr := errors.New("close failed")
+	ensemble, err := NewEnsemble(EnsembleConfig{Vocabulary: []string{"person"}},
+		EnsembleMember{Net: &staticNet{}},
+		EnsembleMember{Net: &staticNet{closeErr: closeErr}},
+	)
+	s.Require().NoError(err)
+	s.ErrorIs(ensemble.Close(), closeErr)
+}
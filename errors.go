@@ -0,0 +1,70 @@
+package yolov5
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+var (
+	// ErrModelNotFound is returned when the model file of a net does not exist.
+	ErrModelNotFound = errors.New("model not found")
+	// ErrLabelsMismatch is returned when the labels do not match the classes predicted by the model.
+	ErrLabelsMismatch = errors.New("labels do not match model")
+	// ErrInvalidInputSize is returned when the input size of the network is invalid.
+	ErrInvalidInputSize = errors.New("invalid input size")
+	// ErrEmptyFrame is returned when a detection is requested for an empty frame.
+	ErrEmptyFrame = errors.New("empty frame")
+	// ErrUnsupportedFrame is returned when a frame can not be converted into an 8-bit 3-channel BGR frame.
+	ErrUnsupportedFrame = errors.New("unsupported frame")
+	// ErrInvalidConfig is returned when a setting of the config is out of range.
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrClosed is returned when a model is reloaded into a net which is closed.
+	ErrClosed = errors.New("net is closed")
+)
+
+// ConfigError describes which field of a config is invalid. It wraps ErrInvalidInputSize for
+// invalid input sizes and ErrInvalidConfig for all other fields.
+type ConfigError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+	Err    error
+}
+
+// Error returns the description of the error.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s %v %s", e.Err, e.Field, e.Value, e.Reason)
+}
+
+// Unwrap returns the sentinel error wrapped by the config error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is returned when a panic occurred during an operation of a net, for example
+// caused by a malformed matrix. The panic is recovered so a single bad frame does not take
+// down the whole process.
+type PanicError struct {
+	Op    string
+	Value interface{}
+	Stack []byte
+}
+
+// Error returns the description of the error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic during %s: %v", e.Op, e.Value)
+}
+
+// Unwrap returns the value of the panic, if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverPanic recovers from a panic and stores it as *PanicError in given error, it should be deferred.
+func recoverPanic(op string, err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Op: op, Value: r, Stack: debug.Stack()}
+	}
+}
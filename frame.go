@@ -0,0 +1,83 @@
+package yolov5
+
+import (
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+// frameConversion describes how a frame is converted into an 8-bit 3-channel BGR frame.
+type frameConversion struct {
+	// convertDepth is set when the depth of the frame is not 8-bit unsigned, the values are
+	// mapped onto [0, 255] using value*alpha + beta
+	convertDepth bool
+	alpha        float32
+	beta         float32
+	// convertColor is set when the frame does not have 3 channels
+	convertColor bool
+	code         gocv.ColorConversionCode
+}
+
+// conversionFor determines the conversion required for a frame of given type and channel count.
+// Floating point frames are expected to contain values within [0, 1].
+func conversionFor(matType gocv.MatType, channels int) (frameConversion, error) {
+	c := frameConversion{alpha: 1}
+	switch depth := matType & 7; depth {
+	case gocv.MatTypeCV8U:
+	case gocv.MatTypeCV8S:
+		c.convertDepth, c.beta = true, 128
+	case gocv.MatTypeCV16U:
+		c.convertDepth, c.alpha = true, 1.0/257
+	case gocv.MatTypeCV16S:
+		c.convertDepth, c.alpha, c.beta = true, 1.0/257, 128
+	case gocv.MatTypeCV32F, gocv.MatTypeCV64F:
+		c.convertDepth, c.alpha = true, 255
+	default:
+		return c, fmt.Errorf("%w: frame depth %d is not supported", ErrUnsupportedFrame, depth)
+	}
+
+	switch channels {
+	case 1:
+		c.convertColor, c.code = true, gocv.ColorGrayToBGR
+	case 3:
+	case 4:
+		c.convertColor, c.code = true, gocv.ColorBGRAToBGR
+	default:
+		return c, fmt.Errorf("%w: frames with %d channels are not supported", ErrUnsupportedFrame, channels)
+	}
+	return c, nil
+}
+
+// normaliseFrame converts given frame into an 8-bit 3-channel BGR frame. The returned release
+// function closes the matrices created by the conversion, the frame itself is returned when
+// no conversion is required.
+func normaliseFrame(frame gocv.Mat) (gocv.Mat, func(), error) {
+	if frame.Empty() {
+		return frame, func() {}, ErrEmptyFrame
+	}
+	c, err := conversionFor(frame.Type(), frame.Channels())
+	if err != nil {
+		return frame, func() {}, err
+	}
+
+	created := []gocv.Mat{}
+	release := func() {
+		for _, mat := range created {
+			// nolint: errcheck
+			mat.Close()
+		}
+	}
+	if c.convertDepth {
+		converted := gocv.NewMat()
+		created = append(created, converted)
+		frame.ConvertToWithParams(&converted, gocv.MatTypeCV8U, c.alpha, c.beta)
+		frame = converted
+	}
+	if c.convertColor {
+		converted := gocv.NewMat()
+		created = append(created, converted)
+		gocv.CvtColor(frame, &converted, c.code)
+		frame = converted
+	}
+	return frame, release, nil
+}
@@ -0,0 +1,87 @@
+package yolov5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+)
+
+type FrameTestSuite struct {
+	suite.Suite
+}
+
+func TestFrameTestSuite(t *testing.T) {
+	suite.Run(t, new(FrameTestSuite))
+}
+
+func (s *FrameTestSuite) TestConversionFor() {
+	tests := []struct {
+		Name     string
+		MatType  gocv.MatType
+		Channels int
+		Expected frameConversion
+		Error    error
+	}{
+		{
+			Name:     "BGR frame",
+			MatType:  gocv.MatTypeCV8UC3,
+			Channels: 3,
+			Expected: frameConversion{alpha: 1},
+		},
+		{
+			Name:     "Grayscale frame",
+			MatType:  gocv.MatTypeCV8UC1,
+			Channels: 1,
+			Expected: frameConversion{alpha: 1, convertColor: true, code: gocv.ColorGrayToBGR},
+		},
+		{
+			Name:     "BGRA frame",
+			MatType:  gocv.MatTypeCV8UC4,
+			Channels: 4,
+			Expected: frameConversion{alpha: 1, convertColor: true, code: gocv.ColorBGRAToBGR},
+		},
+		{
+			Name:     "16-bit grayscale frame",
+			MatType:  gocv.MatTypeCV16UC1,
+			Channels: 1,
+			Expected: frameConversion{convertDepth: true, alpha: 1.0 / 257, convertColor: true, code: gocv.ColorGrayToBGR},
+		},
+		{
+			Name:     "16-bit signed frame",
+			MatType:  gocv.MatTypeCV16SC3,
+			Channels: 3,
+			Expected: frameConversion{convertDepth: true, alpha: 1.0 / 257, beta: 128},
+		},
+		{
+			Name:     "Floating point frame",
+			MatType:  gocv.MatTypeCV32FC3,
+			Channels: 3,
+			Expected: frameConversion{convertDepth: true, alpha: 255},
+		},
+		{
+			Name:     "32-bit integer frame",
+			MatType:  gocv.MatTypeCV32SC3,
+			Channels: 3,
+			Error:    ErrUnsupportedFrame,
+		},
+		{
+			Name:     "Two channel frame",
+			MatType:  gocv.MatTypeCV8UC2,
+			Channels: 2,
+			Error:    ErrUnsupportedFrame,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			conversion, err := conversionFor(test.MatType, test.Channels)
+			if test.Error != nil {
+				s.ErrorIs(err, test.Error)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Expected, conversion)
+		})
+	}
+}
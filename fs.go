@@ -0,0 +1,32 @@
+package yolov5
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// NewNetFromFS creates new yolo net for given model and coco names paths within the given
+// file system, e.g. an embed.FS for single binary deployments.
+func NewNetFromFS(fsys fs.FS, modelPath, cocoNamePath string, config Config) (Net, error) {
+	modelBytes, err := fs.ReadFile(fsys, modelPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", ErrModelNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cocoNames, err := fsys.Open(cocoNamePath)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer cocoNames.Close()
+
+	if config.ModelID == "" {
+		config.ModelID = path.Base(modelPath)
+	}
+	return NewNetFromBytes(modelBytes, cocoNames, config)
+}
@@ -0,0 +1,77 @@
+package yolov5
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/refnet"
+)
+
+type FSTestSuite struct {
+	suite.Suite
+}
+
+func TestFSTestSuite(t *testing.T) {
+	suite.Run(t, new(FSTestSuite))
+}
+
+func (s *FSTestSuite) TestNewNetFromBytes() {
+	neuralNetMock := refnet.New(2)
+	config := DefaultConfig()
+	config.NewNetFromBytes = func(model []byte) (ml.NeuralNet, error) {
+		s.Equal([]byte("onnx"), model)
+		return neuralNetMock, nil
+	}
+
+	net, err := NewNetFromBytes([]byte("onnx"), strings.NewReader("laptop\ncoffee"), config)
+	s.Require().NoError(err)
+	yoloNet := net.(*yoloNet)
+	s.Equal(neuralNetMock, yoloNet.model.net)
+	s.Equal([]string{"laptop", "coffee"}, yoloNet.model.cocoNames)
+}
+
+func (s *FSTestSuite) TestNewNetFromBytesUnableToCreateNet() {
+	config := DefaultConfig()
+	config.NewNetFromBytes = func([]byte) (ml.NeuralNet, error) {
+		return nil, fmt.Errorf("very broken")
+	}
+
+	_, err := NewNetFromBytes([]byte("onnx"), strings.NewReader("laptop\ncoffee"), config)
+	s.Equal(fmt.Errorf("very broken"), err)
+}
+
+func (s *FSTestSuite) TestNewNetFromFS() {
+	fsys := fstest.MapFS{
+		"models/yolov5s.onnx": &fstest.MapFile{Data: []byte("onnx")},
+		"models/coco.names":   &fstest.MapFile{Data: []byte("laptop\ncoffee")},
+	}
+	neuralNetMock := refnet.New(2)
+	config := DefaultConfig()
+	config.NewNetFromBytes = func(model []byte) (ml.NeuralNet, error) {
+		s.Equal([]byte("onnx"), model)
+		return neuralNetMock, nil
+	}
+
+	net, err := NewNetFromFS(fsys, "models/yolov5s.onnx", "models/coco.names", config)
+	s.Require().NoError(err)
+	yoloNet := net.(*yoloNet)
+	s.Equal("yolov5s.onnx", yoloNet.model.modelID)
+	s.Equal([]string{"laptop", "coffee"}, yoloNet.model.cocoNames)
+}
+
+func (s *FSTestSuite) TestNewNetFromFSMissingFiles() {
+	fsys := fstest.MapFS{
+		"models/yolov5s.onnx": &fstest.MapFile{Data: []byte("onnx")},
+	}
+
+	_, err := NewNetFromFS(fsys, "models/notexistent", "models/coco.names", DefaultConfig())
+	s.ErrorIs(err, ErrModelNotFound)
+
+	_, err = NewNetFromFS(fsys, "models/yolov5s.onnx", "models/notexistent", DefaultConfig())
+	s.Error(err)
+}
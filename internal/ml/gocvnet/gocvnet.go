@@ -0,0 +1,141 @@
+// Package gocvnet provides the neural network backed by the gocv (OpenCV) DNN module.
+package gocvnet
+
+import (
+	"fmt"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+)
+
+// Targetable is implemented by neural networks on which the preferable
+// backend and target of the OpenCV DNN module can be set.
+type Targetable interface {
+	SetPreferableBackend(backend gocv.NetBackendType) error
+	SetPreferableTarget(target gocv.NetTargetType) error
+}
+
+// Net the neural network implementation using the gocv DNN module.
+type Net struct {
+	net         gocv.Net
+	lastForward time.Duration
+}
+
+// ReadONNX reads the ONNX model of given path.
+func ReadONNX(modelPath string) *Net {
+	return &Net{net: gocv.ReadNetFromONNX(modelPath)}
+}
+
+// ReadONNXBytes reads the ONNX model from memory.
+func ReadONNXBytes(model []byte) (*Net, error) {
+	net, err := gocv.ReadNetFromONNXBytes(model)
+	if err != nil {
+		return nil, err
+	}
+	return &Net{net: net}, nil
+}
+
+// SetPreferableBackend sets the computation backend of the net.
+func (n *Net) SetPreferableBackend(backend gocv.NetBackendType) error {
+	return n.net.SetPreferableBackend(backend)
+}
+
+// SetPreferableTarget sets the target device of the net.
+func (n *Net) SetPreferableTarget(target gocv.NetTargetType) error {
+	return n.net.SetPreferableTarget(target)
+}
+
+// Forward runs the inference for given input. The returned tensors are backed by gocv
+// matrices and should be closed by the caller.
+func (n *Net) Forward(input ml.Tensor) ([]ml.Tensor, error) {
+	blob, ok := input.(*MatTensor)
+	if !ok {
+		mat, err := NewMatTensor(input)
+		if err != nil {
+			return nil, err
+		}
+		// nolint: errcheck
+		defer mat.Close()
+		blob = mat
+	}
+
+	n.net.SetInput(blob.Mat, "")
+	layerIDs := n.net.GetUnconnectedOutLayers()
+	fl := []string{}
+
+	for _, id := range layerIDs {
+		layer := n.net.GetLayer(id)
+		fl = append(fl, layer.GetName())
+	}
+	outputs := n.net.ForwardLayers(fl)
+	n.lastForward = perfProfileDuration(n.net.GetPerfProfile())
+
+	tensors := make([]ml.Tensor, 0, len(outputs))
+	for _, output := range outputs {
+		tensors = append(tensors, &MatTensor{Mat: output})
+	}
+	return tensors, nil
+}
+
+// LastForwardDuration returns the duration of the last forward pass, as reported by the perf profile of the net.
+func (n *Net) LastForwardDuration() time.Duration {
+	return n.lastForward
+}
+
+// Close closes the net.
+func (n *Net) Close() error {
+	return n.net.Close()
+}
+
+// perfProfileDuration converts the ticks reported by the perf profile of the net into a duration.
+func perfProfileDuration(ticks float64) time.Duration {
+	frequency := gocv.GetTickFrequency()
+	if frequency <= 0 {
+		return 0
+	}
+	return time.Duration(ticks / frequency * float64(time.Second))
+}
+
+// MatTensor a tensor backed by a gocv matrix.
+type MatTensor struct {
+	Mat gocv.Mat
+}
+
+// NewMatTensor copies given tensor into a gocv matrix.
+func NewMatTensor(t ml.Tensor) (*MatTensor, error) {
+	mat := gocv.NewMatWithSizes(t.Shape(), gocv.MatTypeCV32F)
+	data, err := mat.DataPtrFloat32()
+	if err != nil {
+		// nolint: errcheck
+		mat.Close()
+		return nil, err
+	}
+	if len(data) != len(t.Data()) {
+		// nolint: errcheck
+		mat.Close()
+		return nil, fmt.Errorf("tensor of shape %v has %d elements", t.Shape(), len(t.Data()))
+	}
+	copy(data, t.Data())
+	return &MatTensor{Mat: mat}, nil
+}
+
+// Shape returns the size of every dimension of the matrix.
+func (t *MatTensor) Shape() []int {
+	return t.Mat.Size()
+}
+
+// Data returns the elements of the matrix, nil is returned if the matrix does not contain float32 elements.
+func (t *MatTensor) Data() []float32 {
+	data, err := t.Mat.DataPtrFloat32()
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Close closes the underlying matrix.
+func (t *MatTensor) Close() error {
+	return t.Mat.Close()
+}
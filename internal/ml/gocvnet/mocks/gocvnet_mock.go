@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gocvnet.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	gocv "gocv.io/x/gocv"
+)
+
+// MockTargetable is a mock of Targetable interface.
+type MockTargetable struct {
+	ctrl     *gomock.Controller
+	recorder *MockTargetableMockRecorder
+}
+
+// MockTargetableMockRecorder is the mock recorder for MockTargetable.
+type MockTargetableMockRecorder struct {
+	mock *MockTargetable
+}
+
+// NewMockTargetable creates a new mock instance.
+func NewMockTargetable(ctrl *gomock.Controller) *MockTargetable {
+	mock := &MockTargetable{ctrl: ctrl}
+	mock.recorder = &MockTargetableMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTargetable) EXPECT() *MockTargetableMockRecorder {
+	return m.recorder
+}
+
+// SetPreferableBackend mocks base method.
+func (m *MockTargetable) SetPreferableBackend(backend gocv.NetBackendType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferableBackend", backend)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferableBackend indicates an expected call of SetPreferableBackend.
+func (mr *MockTargetableMockRecorder) SetPreferableBackend(backend interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferableBackend", reflect.TypeOf((*MockTargetable)(nil).SetPreferableBackend), backend)
+}
+
+// SetPreferableTarget mocks base method.
+func (m *MockTargetable) SetPreferableTarget(target gocv.NetTargetType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferableTarget", target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferableTarget indicates an expected call of SetPreferableTarget.
+func (mr *MockTargetableMockRecorder) SetPreferableTarget(target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferableTarget", reflect.TypeOf((*MockTargetable)(nil).SetPreferableTarget), target)
+}
@@ -1,16 +1,61 @@
 // Package ml is used as interface on how a neural network should behave
 package ml
 
-import "gocv.io/x/gocv"
+import (
+	"fmt"
+	"time"
+)
 
-// NeuralNet is the interface representing the neural network
+// Tensor is the backend neutral representation of the in- and outputs of a neural network.
+// Tensors returned by a neural network which implement io.Closer should be closed by the
+// caller once they are no longer used.
+type Tensor interface {
+	// Shape returns the size of every dimension of the tensor
+	Shape() []int
+	// Data returns the elements of the tensor in row-major order
+	Data() []float32
+}
+
+// NeuralNet is the interface representing the inference engine of the neural network
 // used for calculating the object detections
 type NeuralNet interface {
-	SetPreferableBackend(backend gocv.NetBackendType) error
-	SetPreferableTarget(target gocv.NetTargetType) error
-	SetInput(blob gocv.Mat, name string)
-	ForwardLayers(outBlobNames []string) (blobs []gocv.Mat)
-	GetUnconnectedOutLayers() (ids []int)
-	GetLayer(id int) gocv.Layer
+	Forward(input Tensor) ([]Tensor, error)
 	Close() error
 }
+
+// Profiler is implemented by neural networks which are able to report
+// how long their last forward pass took.
+type Profiler interface {
+	LastForwardDuration() time.Duration
+}
+
+// DenseTensor a tensor backed by a float32 slice.
+type DenseTensor struct {
+	shape []int
+	data  []float32
+}
+
+// NewTensor creates a new dense tensor for given shape and data.
+func NewTensor(shape []int, data []float32) (*DenseTensor, error) {
+	size := 1
+	for _, dim := range shape {
+		size *= dim
+	}
+	if size != len(data) {
+		return nil, fmt.Errorf("tensor of shape %v requires %d elements, got %d", shape, size, len(data))
+	}
+	return &DenseTensor{
+		shape: shape,
+		data:  data,
+	}, nil
+}
+
+// Shape returns the size of every dimension of the tensor.
+func (t *DenseTensor) Shape() []int {
+	return t.shape
+}
+
+// Data returns the elements of the tensor.
+func (t *DenseTensor) Data() []float32 {
+	return t.data
+}
@@ -1,4 +1,3 @@
-
 // Code generated by MockGen. DO NOT EDIT.
 // Source: ml.go
 
@@ -7,11 +6,63 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	gocv "gocv.io/x/gocv"
+	ml "github.com/wimspaargaren/yolov5/internal/ml"
 )
 
+// MockTensor is a mock of Tensor interface.
+type MockTensor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTensorMockRecorder
+}
+
+// MockTensorMockRecorder is the mock recorder for MockTensor.
+type MockTensorMockRecorder struct {
+	mock *MockTensor
+}
+
+// NewMockTensor creates a new mock instance.
+func NewMockTensor(ctrl *gomock.Controller) *MockTensor {
+	mock := &MockTensor{ctrl: ctrl}
+	mock.recorder = &MockTensorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTensor) EXPECT() *MockTensorMockRecorder {
+	return m.recorder
+}
+
+// Data mocks base method.
+func (m *MockTensor) Data() []float32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Data")
+	ret0, _ := ret[0].([]float32)
+	return ret0
+}
+
+// Data indicates an expected call of Data.
+func (mr *MockTensorMockRecorder) Data() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Data", reflect.TypeOf((*MockTensor)(nil).Data))
+}
+
+// Shape mocks base method.
+func (m *MockTensor) Shape() []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shape")
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// Shape indicates an expected call of Shape.
+func (mr *MockTensorMockRecorder) Shape() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shape", reflect.TypeOf((*MockTensor)(nil).Shape))
+}
+
 // MockNeuralNet is a mock of NeuralNet interface.
 type MockNeuralNet struct {
 	ctrl     *gomock.Controller
@@ -49,84 +100,54 @@ func (mr *MockNeuralNetMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockNeuralNet)(nil).Close))
 }
 
-// ForwardLayers mocks base method.
-func (m *MockNeuralNet) ForwardLayers(outBlobNames []string) []gocv.Mat {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ForwardLayers", outBlobNames)
-	ret0, _ := ret[0].([]gocv.Mat)
-	return ret0
-}
-
-// ForwardLayers indicates an expected call of ForwardLayers.
-func (mr *MockNeuralNetMockRecorder) ForwardLayers(outBlobNames interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForwardLayers", reflect.TypeOf((*MockNeuralNet)(nil).ForwardLayers), outBlobNames)
-}
-
-// GetLayer mocks base method.
-func (m *MockNeuralNet) GetLayer(id int) gocv.Layer {
+// Forward mocks base method.
+func (m *MockNeuralNet) Forward(input ml.Tensor) ([]ml.Tensor, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLayer", id)
-	ret0, _ := ret[0].(gocv.Layer)
-	return ret0
+	ret := m.ctrl.Call(m, "Forward", input)
+	ret0, _ := ret[0].([]ml.Tensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// GetLayer indicates an expected call of GetLayer.
-func (mr *MockNeuralNetMockRecorder) GetLayer(id interface{}) *gomock.Call {
+// Forward indicates an expected call of Forward.
+func (mr *MockNeuralNetMockRecorder) Forward(input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLayer", reflect.TypeOf((*MockNeuralNet)(nil).GetLayer), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Forward", reflect.TypeOf((*MockNeuralNet)(nil).Forward), input)
 }
 
-// GetUnconnectedOutLayers mocks base method.
-func (m *MockNeuralNet) GetUnconnectedOutLayers() []int {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUnconnectedOutLayers")
-	ret0, _ := ret[0].([]int)
-	return ret0
+// MockProfiler is a mock of Profiler interface.
+type MockProfiler struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfilerMockRecorder
 }
 
-// GetUnconnectedOutLayers indicates an expected call of GetUnconnectedOutLayers.
-func (mr *MockNeuralNetMockRecorder) GetUnconnectedOutLayers() *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnconnectedOutLayers", reflect.TypeOf((*MockNeuralNet)(nil).GetUnconnectedOutLayers))
+// MockProfilerMockRecorder is the mock recorder for MockProfiler.
+type MockProfilerMockRecorder struct {
+	mock *MockProfiler
 }
 
-// SetInput mocks base method.
-func (m *MockNeuralNet) SetInput(blob gocv.Mat, name string) {
-	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetInput", blob, name)
+// NewMockProfiler creates a new mock instance.
+func NewMockProfiler(ctrl *gomock.Controller) *MockProfiler {
+	mock := &MockProfiler{ctrl: ctrl}
+	mock.recorder = &MockProfilerMockRecorder{mock}
+	return mock
 }
 
-// SetInput indicates an expected call of SetInput.
-func (mr *MockNeuralNetMockRecorder) SetInput(blob, name interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInput", reflect.TypeOf((*MockNeuralNet)(nil).SetInput), blob, name)
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfiler) EXPECT() *MockProfilerMockRecorder {
+	return m.recorder
 }
 
-// SetPreferableBackend mocks base method.
-func (m *MockNeuralNet) SetPreferableBackend(backend gocv.NetBackendType) error {
+// LastForwardDuration mocks base method.
+func (m *MockProfiler) LastForwardDuration() time.Duration {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPreferableBackend", backend)
-	ret0, _ := ret[0].(error)
+	ret := m.ctrl.Call(m, "LastForwardDuration")
+	ret0, _ := ret[0].(time.Duration)
 	return ret0
 }
 
-// SetPreferableBackend indicates an expected call of SetPreferableBackend.
-func (mr *MockNeuralNetMockRecorder) SetPreferableBackend(backend interface{}) *gomock.Call {
+// LastForwardDuration indicates an expected call of LastForwardDuration.
+func (mr *MockProfilerMockRecorder) LastForwardDuration() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferableBackend", reflect.TypeOf((*MockNeuralNet)(nil).SetPreferableBackend), backend)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastForwardDuration", reflect.TypeOf((*MockProfiler)(nil).LastForwardDuration))
 }
-
-// SetPreferableTarget mocks base method.
-func (m *MockNeuralNet) SetPreferableTarget(target gocv.NetTargetType) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPreferableTarget", target)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// SetPreferableTarget indicates an expected call of SetPreferableTarget.
-func (mr *MockNeuralNetMockRecorder) SetPreferableTarget(target interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferableTarget", reflect.TypeOf((*MockNeuralNet)(nil).SetPreferableTarget), target)
-}
\ No newline at end of file
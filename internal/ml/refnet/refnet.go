@@ -0,0 +1,85 @@
+// Package refnet provides a pure Go reference implementation of the neural network. Rather
+// than running an actual model, it renders a configured set of objects into an output tensor
+// shaped like the output of YOLOv5, which makes it possible to test the postprocessing
+// without OpenCV.
+package refnet
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+)
+
+// strides of the three detection heads of YOLOv5, every head predicts three anchors per cell.
+var strides = []int{8, 16, 32}
+
+// Object an object which the reference net "detects", the bounding box is in network input coordinates.
+type Object struct {
+	ClassID     int
+	Confidence  float32
+	BoundingBox image.Rectangle
+}
+
+// Net the reference neural network.
+type Net struct {
+	numClasses int
+	objects    []Object
+}
+
+// New creates a reference net predicting given objects for a model with given amount of classes.
+func New(numClasses int, objects ...Object) *Net {
+	return &Net{
+		numClasses: numClasses,
+		objects:    objects,
+	}
+}
+
+// Forward renders the objects into an output tensor of shape [1, rows, 5 + classes], where
+// the amount of rows is determined by the input size of given NCHW input tensor.
+func (n *Net) Forward(input ml.Tensor) ([]ml.Tensor, error) {
+	shape := input.Shape()
+	if len(shape) != 4 || shape[0] != 1 || shape[1] != 3 {
+		return nil, fmt.Errorf("expected input of shape [1 3 height width], got %v", shape)
+	}
+	rows := Rows(shape[3], shape[2])
+	if len(n.objects) > rows {
+		return nil, fmt.Errorf("unable to render %d objects in %d rows", len(n.objects), rows)
+	}
+
+	stepSize := 5 + n.numClasses
+	data := make([]float32, rows*stepSize)
+	for i, object := range n.objects {
+		if object.ClassID < 0 || object.ClassID >= n.numClasses {
+			return nil, fmt.Errorf("class id %d out of range [0, %d)", object.ClassID, n.numClasses)
+		}
+		box := object.BoundingBox
+		row := data[i*stepSize : (i+1)*stepSize]
+		row[0] = float32(box.Min.X+box.Max.X) / 2
+		row[1] = float32(box.Min.Y+box.Max.Y) / 2
+		row[2] = float32(box.Dx())
+		row[3] = float32(box.Dy())
+		row[4] = object.Confidence
+		row[5+object.ClassID] = 1
+	}
+
+	output, err := ml.NewTensor([]int{1, rows, stepSize}, data)
+	if err != nil {
+		return nil, err
+	}
+	return []ml.Tensor{output}, nil
+}
+
+// Close closes the net.
+func (n *Net) Close() error {
+	return nil
+}
+
+// Rows returns the amount of predictions YOLOv5 makes for given input size.
+func Rows(width, height int) int {
+	rows := 0
+	for _, stride := range strides {
+		rows += 3 * (width / stride) * (height / stride)
+	}
+	return rows
+}
@@ -0,0 +1,76 @@
+package refnet
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+)
+
+type RefNetTestSuite struct {
+	suite.Suite
+}
+
+func TestRefNetTestSuite(t *testing.T) {
+	suite.Run(t, new(RefNetTestSuite))
+}
+
+func (s *RefNetTestSuite) TestCorrectImplementation() {
+	var _ ml.NeuralNet = &Net{}
+}
+
+func (s *RefNetTestSuite) TestRows() {
+	s.Equal(25200, Rows(640, 640))
+	s.Equal(6300, Rows(320, 320))
+}
+
+func (s *RefNetTestSuite) TestForward() {
+	net := New(2, Object{ClassID: 1, Confidence: 0.9, BoundingBox: image.Rect(10, 20, 30, 60)})
+	input, err := ml.NewTensor([]int{1, 3, 320, 320}, make([]float32, 3*320*320))
+	s.Require().NoError(err)
+
+	outputs, err := net.Forward(input)
+	s.Require().NoError(err)
+	s.Require().Len(outputs, 1)
+	s.Equal([]int{1, 6300, 7}, outputs[0].Shape())
+	s.Equal([]float32{20, 40, 20, 40, 0.9, 0, 1}, outputs[0].Data()[:7])
+	s.Equal(make([]float32, 7), outputs[0].Data()[7:14])
+}
+
+func (s *RefNetTestSuite) TestForwardInvalidInput() {
+	tests := []struct {
+		Name  string
+		Net   *Net
+		Shape []int
+	}{
+		{
+			Name:  "Incorrect amount of dimensions",
+			Net:   New(2),
+			Shape: []int{3, 32, 32},
+		},
+		{
+			Name:  "Incorrect amount of channels",
+			Net:   New(2),
+			Shape: []int{1, 1, 32, 32},
+		},
+		{
+			Name:  "Class id out of range",
+			Net:   New(2, Object{ClassID: 2}),
+			Shape: []int{1, 3, 32, 32},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			size := 1
+			for _, dim := range test.Shape {
+				size *= dim
+			}
+			input, err := ml.NewTensor(test.Shape, make([]float32, size))
+			s.Require().NoError(err)
+			_, err = test.Net.Forward(input)
+			s.Error(err)
+		})
+	}
+}
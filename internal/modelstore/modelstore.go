@@ -0,0 +1,303 @@
+// Package modelstore manages a local cache of models described by manifests. Every model is
+// stored in a directory named after the model, which contains the manifest and its files.
+package modelstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wimspaargaren/yolov5/manifest"
+)
+
+// ManifestFile is the name of the manifest within the directory of a cached model.
+const ManifestFile = "manifest.json"
+
+// ErrNotFound is returned when a model is not present in the store.
+var ErrNotFound = errors.New("model not found")
+
+// Store a local cache of models.
+type Store struct {
+	// Dir is the directory in which the models are stored
+	Dir string
+	// Mirrors are base URLs which are tried before the URLs of the manifest, the
+	// file is requested from the mirror using the base name of its path
+	Mirrors []string
+	// Client used for downloading, defaults to http.DefaultClient
+	Client *http.Client
+	// Log receives progress messages, it may be nil
+	Log io.Writer
+}
+
+// New creates a new store in given directory.
+func New(dir string) *Store {
+	return &Store{
+		Dir:    dir,
+		Client: http.DefaultClient,
+	}
+}
+
+// Pull downloads the files of given manifest into the store, partial downloads of an earlier
+// pull are resumed. Every file is verified against the checksum of the manifest. When pin is
+// set, the checksums of unpinned files are computed after downloading and stored in the cached
+// manifest, otherwise unpinned files are rejected. The cached manifest is returned.
+func (s *Store) Pull(ctx context.Context, m *manifest.Manifest, pin bool) (*manifest.Manifest, error) {
+	if !pin && !m.Pinned() {
+		return nil, fmt.Errorf("%s: %w, use pin to trust the downloaded files", m.ID(), manifest.ErrUnpinned)
+	}
+	dir, err := s.modelDir(m.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *m
+	s.reusePins(&cached)
+	for _, file := range []*manifest.File{&cached.Model, &cached.Labels} {
+		dst, err := localPath(dir, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		err = s.pullFile(ctx, *file, dst)
+		if err != nil {
+			return nil, err
+		}
+		if file.SHA256 == "" {
+			file.SHA256, err = manifest.FileSHA256(dst)
+			if err != nil {
+				return nil, err
+			}
+			s.logf("pinned %s to sha256 %s\n", file.Path, file.SHA256)
+		}
+	}
+
+	err = cached.Write(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Load(filepath.Join(dir, ManifestFile))
+}
+
+// reusePins copies the checksums which were pinned by an earlier pull of the same version.
+func (s *Store) reusePins(m *manifest.Manifest) {
+	previous, err := s.Get(m.Name)
+	if err != nil || previous.Version != m.Version {
+		return
+	}
+	if m.Model.SHA256 == "" && m.Model.Path == previous.Model.Path {
+		m.Model.SHA256 = previous.Model.SHA256
+	}
+	if m.Labels.SHA256 == "" && m.Labels.Path == previous.Labels.Path {
+		m.Labels.SHA256 = previous.Labels.SHA256
+	}
+}
+
+// List returns the manifests of all models in the store, sorted on name.
+func (s *Store) List() ([]*manifest.Manifest, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []*manifest.Manifest{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		m, err := manifest.Load(filepath.Join(s.Dir, entry.Name(), ManifestFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Name < manifests[j].Name
+	})
+	return manifests, nil
+}
+
+// Get returns the cached manifest of the model with given name.
+func (s *Store) Get(name string) (*manifest.Manifest, error) {
+	dir, err := s.modelDir(name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.Load(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	return m, err
+}
+
+// Verify verifies the files of the model with given name against its cached manifest.
+func (s *Store) Verify(name string) error {
+	m, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	return m.Verify()
+}
+
+// Remove removes the model with given name from the store.
+func (s *Store) Remove(name string) error {
+	_, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	dir, err := s.modelDir(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// pullFile downloads given file to dst, unless dst already matches the checksum of the file.
+func (s *Store) pullFile(ctx context.Context, file manifest.File, dst string) error {
+	if file.SHA256 != "" && manifest.VerifyFile(dst, file.SHA256) == nil {
+		s.logf("%s is up to date\n", file.Path)
+		return nil
+	}
+
+	urls := s.urls(file)
+	if len(urls) == 0 {
+		return fmt.Errorf("no urls to download %s from", file.Path)
+	}
+	err := os.MkdirAll(filepath.Dir(dst), 0o755)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, u := range urls {
+		s.logf("downloading %s from %s\n", file.Path, u)
+		err := s.download(ctx, u, dst+".part")
+		if err == nil && file.SHA256 != "" {
+			err = manifest.VerifyFile(dst+".part", file.SHA256)
+			if err != nil {
+				// A corrupt partial download can not be resumed
+				// nolint: errcheck
+				os.Remove(dst + ".part")
+			}
+		}
+		if err == nil {
+			return os.Rename(dst+".part", dst)
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", u, err))
+	}
+	return fmt.Errorf("unable to download %s: %w", file.Path, errors.Join(errs...))
+}
+
+// urls returns the urls from which given file can be downloaded, mirrors first.
+func (s *Store) urls(file manifest.File) []string {
+	urls := []string{}
+	for _, mirror := range s.Mirrors {
+		u, err := url.JoinPath(mirror, path.Base(filepath.ToSlash(file.Path)))
+		if err == nil {
+			urls = append(urls, u)
+		}
+	}
+	return append(urls, file.URLs...)
+}
+
+// download downloads given url into dst, resuming the download if dst already exists.
+func (s *Store) download(ctx context.Context, u, dst string) error {
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	// nolint: errcheck
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		s.logf("resuming download at %d bytes\n", offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial download is already complete
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		// The server does not support ranges, start over
+		err = f.Truncate(0)
+		if err != nil {
+			return err
+		}
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	_, err = io.Copy(f, resp.Body)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// modelDir returns the directory of the model with given name.
+func (s *Store) modelDir(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid model name %q", name)
+	}
+	return filepath.Join(s.Dir, name), nil
+}
+
+// localPath returns the path of a file of the manifest within the directory of the model.
+func localPath(dir, file string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(file))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside of the model directory", file)
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+func (s *Store) logf(format string, args ...interface{}) {
+	if s.Log != nil {
+		fmt.Fprintf(s.Log, format, args...)
+	}
+}
@@ -0,0 +1,196 @@
+package modelstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/manifest"
+)
+
+var (
+	modelContent  = bytes.Repeat([]byte("onnx"), 1024)
+	labelsContent = []byte("person\nbicycle\n")
+)
+
+type ModelStoreTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	store  *Store
+
+	mu       sync.Mutex
+	requests []*http.Request
+	files    map[string][]byte
+}
+
+func TestModelStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(ModelStoreTestSuite))
+}
+
+func (s *ModelStoreTestSuite) SetupTest() {
+	s.requests = nil
+	s.files = map[string][]byte{
+		"/origin/yolov5s.onnx": modelContent,
+		"/origin/coco.names":   labelsContent,
+		"/mirror/coco.names":   labelsContent,
+		"/corrupt/yolov5s.onnx": func() []byte {
+			corrupt := append([]byte{}, modelContent...)
+			corrupt[0] = 'x'
+			return corrupt
+		}(),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		content, ok := s.files[r.URL.Path]
+		s.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, filepath.Base(r.URL.Path), time.Time{}, bytes.NewReader(content))
+	}))
+	s.store = New(s.T().TempDir())
+	s.store.Client = s.server.Client()
+}
+
+func (s *ModelStoreTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ModelStoreTestSuite) manifest(modelURLs ...string) *manifest.Manifest {
+	return &manifest.Manifest{
+		Name:    "yolov5",
+		Version: "6.0",
+		Model: manifest.File{
+			Path:   "yolov5s.onnx",
+			SHA256: checksum(modelContent),
+			URLs:   modelURLs,
+		},
+		Labels: manifest.File{
+			Path:   "coco.names",
+			SHA256: checksum(labelsContent),
+			URLs:   []string{s.server.URL + "/origin/coco.names"},
+		},
+	}
+}
+
+func (s *ModelStoreTestSuite) TestPullListVerifyRemove() {
+	cached, err := s.store.Pull(context.Background(), s.manifest(s.server.URL+"/origin/yolov5s.onnx"), false)
+	s.Require().NoError(err)
+	s.Equal(filepath.Join(s.store.Dir, "yolov5", "yolov5s.onnx"), cached.ModelPath())
+	s.NoError(cached.Verify())
+
+	manifests, err := s.store.List()
+	s.Require().NoError(err)
+	s.Require().Len(manifests, 1)
+	s.Equal("yolov5@6.0", manifests[0].ID())
+
+	s.NoError(s.store.Verify("yolov5"))
+	s.Require().NoError(os.WriteFile(cached.LabelsPath(), []byte("corrupted"), 0o600))
+	var mismatch *manifest.ChecksumMismatchError
+	s.True(errors.As(s.store.Verify("yolov5"), &mismatch))
+
+	s.NoError(s.store.Remove("yolov5"))
+	s.True(errors.Is(s.store.Verify("yolov5"), ErrNotFound))
+	s.True(errors.Is(s.store.Remove("yolov5"), ErrNotFound))
+}
+
+func (s *ModelStoreTestSuite) TestPullUpToDate() {
+	m := s.manifest(s.server.URL + "/origin/yolov5s.onnx")
+	_, err := s.store.Pull(context.Background(), m, false)
+	s.Require().NoError(err)
+	s.Len(s.requests, 2)
+
+	_, err = s.store.Pull(context.Background(), m, false)
+	s.Require().NoError(err)
+	s.Len(s.requests, 2)
+}
+
+func (s *ModelStoreTestSuite) TestResumePartialDownload() {
+	dir := filepath.Join(s.store.Dir, "yolov5")
+	s.Require().NoError(os.MkdirAll(dir, 0o755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "yolov5s.onnx.part"), modelContent[:1000], 0o600))
+
+	cached, err := s.store.Pull(context.Background(), s.manifest(s.server.URL+"/origin/yolov5s.onnx"), false)
+	s.Require().NoError(err)
+	s.NoError(cached.Verify())
+	s.Equal("bytes=1000-", s.requests[0].Header.Get("Range"))
+	s.NoFileExists(filepath.Join(dir, "yolov5s.onnx.part"))
+}
+
+func (s *ModelStoreTestSuite) TestFallbackOnChecksumMismatch() {
+	cached, err := s.store.Pull(context.Background(), s.manifest(
+		s.server.URL+"/corrupt/yolov5s.onnx",
+		s.server.URL+"/origin/yolov5s.onnx",
+	), false)
+	s.Require().NoError(err)
+	s.NoError(cached.Verify())
+}
+
+func (s *ModelStoreTestSuite) TestMirrors() {
+	s.store.Mirrors = []string{s.server.URL + "/unavailable", s.server.URL + "/mirror"}
+	s.files["/mirror/yolov5s.onnx"] = modelContent
+
+	cached, err := s.store.Pull(context.Background(), s.manifest(), false)
+	s.Require().NoError(err)
+	s.NoError(cached.Verify())
+	for _, r := range s.requests {
+		s.NotEqual("/origin/yolov5s.onnx", r.URL.Path)
+	}
+}
+
+func (s *ModelStoreTestSuite) TestUnableToDownload() {
+	_, err := s.store.Pull(context.Background(), s.manifest(s.server.URL+"/notexistent/yolov5s.onnx"), false)
+	s.Error(err)
+
+	_, err = s.store.Pull(context.Background(), s.manifest(), false)
+	s.Error(err)
+}
+
+func (s *ModelStoreTestSuite) TestPin() {
+	m := s.manifest(s.server.URL + "/origin/yolov5s.onnx")
+	m.Model.SHA256 = ""
+
+	_, err := s.store.Pull(context.Background(), m, false)
+	s.True(errors.Is(err, manifest.ErrUnpinned))
+
+	cached, err := s.store.Pull(context.Background(), m, true)
+	s.Require().NoError(err)
+	s.Equal(checksum(modelContent), cached.Model.SHA256)
+	s.NoError(s.store.Verify("yolov5"))
+
+	// The pinned checksum is reused, so the model is not downloaded again
+	requests := len(s.requests)
+	_, err = s.store.Pull(context.Background(), m, true)
+	s.Require().NoError(err)
+	s.Len(s.requests, requests)
+}
+
+func (s *ModelStoreTestSuite) TestInvalidPaths() {
+	m := s.manifest(s.server.URL + "/origin/yolov5s.onnx")
+	m.Model.Path = "../yolov5s.onnx"
+	_, err := s.store.Pull(context.Background(), m, false)
+	s.Error(err)
+
+	m = s.manifest(s.server.URL + "/origin/yolov5s.onnx")
+	m.Name = "../yolov5"
+	_, err = s.store.Pull(context.Background(), m, false)
+	s.Error(err)
+}
@@ -0,0 +1,113 @@
+package postprocess
+
+import (
+	"image"
+	"sort"
+)
+
+// WeightedBoxFusion fuses the detections of several models using weighted box fusion. The
+// detections of all models are clustered per class, a detection joins the cluster of the fused
+// box it overlaps most with when their intersection over union exceeds the threshold. The
+// coordinates of a fused box are the confidence weighted average of its cluster. Its confidence
+// is the weighted average of the highest confidence every model has in the cluster, so a box
+// which is missed by some of the models is penalised. Detections are given per model, weights
+// contains the weight of every model. The fused detections are sorted on confidence, highest first.
+func WeightedBoxFusion(detections [][]Detection, weights []float32, iouThreshold float32) []Detection {
+	totalWeight := float32(0)
+	all := []member{}
+	for model, modelDetections := range detections {
+		totalWeight += weights[model]
+		for _, detection := range modelDetections {
+			all = append(all, member{
+				model:      model,
+				detection:  detection,
+				confidence: detection.Confidence * weights[model],
+			})
+		}
+	}
+	if totalWeight <= 0 {
+		return []Detection{}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].confidence > all[j].confidence
+	})
+
+	clusters := []*cluster{}
+	for _, m := range all {
+		var best *cluster
+		bestIoU := iouThreshold
+		for _, c := range clusters {
+			if c.fused.ClassID != m.detection.ClassID {
+				continue
+			}
+			iou := IoU(c.fused.BoundingBox, m.detection.BoundingBox)
+			if iou > bestIoU {
+				best = c
+				bestIoU = iou
+			}
+		}
+		if best == nil {
+			best = &cluster{fused: Detection{ClassID: m.detection.ClassID}}
+			clusters = append(clusters, best)
+		}
+		best.members = append(best.members, m)
+		best.fused.BoundingBox = fuseBoxes(best.members)
+	}
+
+	result := make([]Detection, 0, len(clusters))
+	for _, c := range clusters {
+		maxPerModel := map[int]float32{}
+		for _, m := range c.members {
+			if m.confidence > maxPerModel[m.model] {
+				maxPerModel[m.model] = m.confidence
+			}
+		}
+		confidence := float32(0)
+		for _, modelConfidence := range maxPerModel {
+			confidence += modelConfidence
+		}
+		c.fused.Confidence = confidence / totalWeight
+		result = append(result, c.fused)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Confidence > result[j].Confidence
+	})
+	return result
+}
+
+// member a detection of one of the models, which is part of a cluster.
+type member struct {
+	model      int
+	detection  Detection
+	confidence float32
+}
+
+// cluster the detections which are fused into a single detection.
+type cluster struct {
+	fused   Detection
+	members []member
+}
+
+// fuseBoxes returns the confidence weighted average of the boxes of given members.
+func fuseBoxes(members []member) image.Rectangle {
+	var minX, minY, maxX, maxY, total float32
+	for _, m := range members {
+		r := m.detection.BoundingBox
+		minX += float32(r.Min.X) * m.confidence
+		minY += float32(r.Min.Y) * m.confidence
+		maxX += float32(r.Max.X) * m.confidence
+		maxY += float32(r.Max.Y) * m.confidence
+		total += m.confidence
+	}
+	if total <= 0 {
+		return members[0].detection.BoundingBox
+	}
+	return image.Rect(round(minX/total), round(minY/total), round(maxX/total), round(maxY/total))
+}
+
+func round(f float32) int {
+	if f < 0 {
+		return int(f - 0.5)
+	}
+	return int(f + 0.5)
+}
@@ -0,0 +1,114 @@
+package postprocess
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FusionTestSuite struct {
+	suite.Suite
+}
+
+func TestFusionTestSuite(t *testing.T) {
+	suite.Run(t, new(FusionTestSuite))
+}
+
+func (s *FusionTestSuite) TestWeightedBoxFusion() {
+	tests := []struct {
+		Name       string
+		Detections [][]Detection
+		Weights    []float32
+		Expected   []Detection
+	}{
+		{
+			Name: "Overlapping boxes are fused",
+			Detections: [][]Detection{
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 20, 20)}},
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(2, 2, 22, 22)}},
+			},
+			Weights: []float32{1, 1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(1, 1, 21, 21)},
+			},
+		},
+		{
+			Name: "Box missed by a model is penalised",
+			Detections: [][]Detection{
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)}},
+				{},
+			},
+			Weights: []float32{1, 1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.4, BoundingBox: image.Rect(0, 0, 10, 10)},
+			},
+		},
+		{
+			Name: "Model weights",
+			Detections: [][]Detection{
+				{{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 10)}},
+				{{ClassID: 0, Confidence: 0.6, BoundingBox: image.Rect(4, 0, 24, 10)}},
+			},
+			Weights: []float32{2, 1},
+			Expected: []Detection{
+				// Coordinates are weighted by 1.8 and 0.6
+				{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(1, 0, 21, 10)},
+			},
+		},
+		{
+			Name: "Different classes are not fused",
+			Detections: [][]Detection{
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)}},
+				{{ClassID: 1, Confidence: 0.6, BoundingBox: image.Rect(0, 0, 10, 10)}},
+			},
+			Weights: []float32{1, 1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.4, BoundingBox: image.Rect(0, 0, 10, 10)},
+				{ClassID: 1, Confidence: 0.3, BoundingBox: image.Rect(0, 0, 10, 10)},
+			},
+		},
+		{
+			Name: "Distant boxes are not fused",
+			Detections: [][]Detection{
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)}},
+				{{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(50, 50, 60, 60)}},
+			},
+			Weights: []float32{1, 1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.4, BoundingBox: image.Rect(0, 0, 10, 10)},
+				{ClassID: 0, Confidence: 0.4, BoundingBox: image.Rect(50, 50, 60, 60)},
+			},
+		},
+		{
+			Name: "Multiple boxes of a single model in a cluster",
+			Detections: [][]Detection{
+				{
+					{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)},
+					{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)},
+				},
+			},
+			Weights: []float32{1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.8, BoundingBox: image.Rect(0, 0, 10, 10)},
+			},
+		},
+		{
+			Name:       "No detections",
+			Detections: [][]Detection{{}, {}},
+			Weights:    []float32{1, 1},
+			Expected:   []Detection{},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			fused := WeightedBoxFusion(test.Detections, test.Weights, 0.55)
+			s.Require().Len(fused, len(test.Expected))
+			for i := range fused {
+				s.Equal(test.Expected[i].ClassID, fused[i].ClassID)
+				s.Equal(test.Expected[i].BoundingBox, fused[i].BoundingBox)
+				s.InDelta(test.Expected[i].Confidence, fused[i].Confidence, 1e-6)
+			}
+		})
+	}
+}
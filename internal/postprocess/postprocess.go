@@ -0,0 +1,157 @@
+// Package postprocess decodes the output of the YOLOv5 network into detections. It only
+// depends on the backend neutral tensors, so it can be used and tested without OpenCV.
+package postprocess
+
+import (
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+)
+
+// Detection a decoded detection, the bounding box is in frame coordinates.
+type Detection struct {
+	ClassID     int
+	Confidence  float32
+	BoundingBox image.Rectangle
+}
+
+// Params the parameters used for decoding the output of the network.
+type Params struct {
+	// ScaleX & ScaleY map network input coordinates onto the frame
+	ScaleX float32
+	ScaleY float32
+	// ConfidenceThreshold is the minimum confidence before an object is considered to be "detected"
+	ConfidenceThreshold float32
+	// NMSThreshold is the non-maximum suppression threshold used for removing overlapping bounding boxes
+	NMSThreshold float32
+	// MaxDetections limits the amount of detections, zero means no limit
+	MaxDetections int
+	// Filter reports whether the detections of given class should be dropped, it may be nil
+	Filter func(classID int) bool
+}
+
+// Decode decodes the output tensor of shape [1, rows, 5 + classes] into detections. Every row
+// contains the box center, box size, objectness and class scores. The detections are filtered
+// on confidence and class, overlapping detections are removed using non-maximum suppression.
+// The detections are sorted on confidence, highest first.
+func Decode(output ml.Tensor, params Params) ([]Detection, error) {
+	shape := output.Shape()
+	if len(shape) != 3 || shape[2] <= 5 {
+		return nil, fmt.Errorf("unexpected output shape: %v", shape)
+	}
+	rows, stepSize := shape[1], shape[2]
+	data := output.Data()
+	if len(data) < rows*stepSize {
+		return nil, fmt.Errorf("output of shape %v contains only %d elements", shape, len(data))
+	}
+
+	detections := []Detection{}
+	for i := 0; i < rows; i++ {
+		row := data[stepSize*i : stepSize*(i+1)]
+		confidence := row[4]
+		if confidence < params.ConfidenceThreshold {
+			continue
+		}
+		classID := ClassID(row[5:])
+		if params.Filter != nil && params.Filter(classID) {
+			continue
+		}
+		detections = append(detections, Detection{
+			ClassID:     classID,
+			Confidence:  confidence,
+			BoundingBox: BoundingBox(row[:4], params.ScaleX, params.ScaleY),
+		})
+	}
+
+	boxes := make([]image.Rectangle, len(detections))
+	scores := make([]float32, len(detections))
+	for i, detection := range detections {
+		boxes[i] = detection.BoundingBox
+		scores[i] = detection.Confidence
+	}
+
+	result := []Detection{}
+	for _, index := range NMS(boxes, scores, params.NMSThreshold) {
+		result = append(result, detections[index])
+		if params.MaxDetections > 0 && len(result) == params.MaxDetections {
+			break
+		}
+	}
+	return result, nil
+}
+
+// ClassID returns the index of the highest class score.
+func ClassID(scores []float32) int {
+	res := 0
+	max := float32(0)
+	for i, y := range scores {
+		if y > max {
+			res = i
+			max = y
+		}
+	}
+	return res
+}
+
+// BoundingBox converts the center x, center y, width and height of a row into a bounding box,
+// scaled by given factors.
+func BoundingBox(row []float32, scaleX, scaleY float32) image.Rectangle {
+	if len(row) < 4 {
+		return image.Rect(0, 0, 0, 0)
+	}
+
+	x, y, w, h := row[0], row[1], row[2], row[3]
+	left := int((x - 0.5*w) * scaleX)
+	top := int((y - 0.5*h) * scaleY)
+	width := int(w * scaleX)
+	height := int(h * scaleY)
+
+	return image.Rect(left, top, left+width, top+height)
+}
+
+// NMS performs greedy non-maximum suppression. It returns the indices of the boxes which are
+// kept, sorted on score, highest first. A box is suppressed when its intersection over union
+// with a box with a higher score exceeds the threshold.
+func NMS(boxes []image.Rectangle, scores []float32, threshold float32) []int {
+	order := make([]int, len(boxes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	kept := []int{}
+	for _, candidate := range order {
+		suppressed := false
+		for _, k := range kept {
+			if IoU(boxes[candidate], boxes[k]) > threshold {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
+
+// IoU returns the intersection over union of two rectangles.
+func IoU(a, b image.Rectangle) float32 {
+	intersection := area(a.Intersect(b))
+	union := area(a) + area(b) - intersection
+	if union <= 0 {
+		return 0
+	}
+	return float32(intersection) / float32(union)
+}
+
+func area(r image.Rectangle) int {
+	if r.Empty() {
+		return 0
+	}
+	return r.Dx() * r.Dy()
+}
@@ -0,0 +1,220 @@
+package postprocess
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/refnet"
+)
+
+type PostprocessTestSuite struct {
+	suite.Suite
+}
+
+func TestPostprocessTestSuite(t *testing.T) {
+	suite.Run(t, new(PostprocessTestSuite))
+}
+
+func (s *PostprocessTestSuite) forward(net *refnet.Net) ml.Tensor {
+	input, err := ml.NewTensor([]int{1, 3, 64, 64}, make([]float32, 3*64*64))
+	s.Require().NoError(err)
+	outputs, err := net.Forward(input)
+	s.Require().NoError(err)
+	return outputs[0]
+}
+
+func (s *PostprocessTestSuite) TestDecode() {
+	laptop := refnet.Object{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)}
+	coffee := refnet.Object{ClassID: 1, Confidence: 0.8, BoundingBox: image.Rect(20, 20, 30, 40)}
+	overlappingCoffee := refnet.Object{ClassID: 1, Confidence: 0.7, BoundingBox: image.Rect(21, 21, 31, 41)}
+	uncertainCoffee := refnet.Object{ClassID: 1, Confidence: 0.3, BoundingBox: image.Rect(50, 50, 60, 60)}
+
+	tests := []struct {
+		Name     string
+		Objects  []refnet.Object
+		Params   Params
+		Expected []Detection
+	}{
+		{
+			Name:    "Two predictions",
+			Objects: []refnet.Object{coffee, laptop},
+			Params:  Params{ScaleX: 1, ScaleY: 1, ConfidenceThreshold: 0.5, NMSThreshold: 0.4},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)},
+				{ClassID: 1, Confidence: 0.8, BoundingBox: image.Rect(20, 20, 30, 40)},
+			},
+		},
+		{
+			Name:    "Scaled to frame",
+			Objects: []refnet.Object{laptop},
+			Params:  Params{ScaleX: 2, ScaleY: 0.5, ConfidenceThreshold: 0.5, NMSThreshold: 0.4},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 5)},
+			},
+		},
+		{
+			Name:    "Result was filtered",
+			Objects: []refnet.Object{coffee, laptop},
+			Params: Params{ScaleX: 1, ScaleY: 1, ConfidenceThreshold: 0.5, NMSThreshold: 0.4, Filter: func(classID int) bool {
+				return classID == 1
+			}},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)},
+			},
+		},
+		{
+			Name:     "Confidence not high enough",
+			Objects:  []refnet.Object{uncertainCoffee},
+			Params:   Params{ScaleX: 1, ScaleY: 1, ConfidenceThreshold: 0.5, NMSThreshold: 0.4},
+			Expected: []Detection{},
+		},
+		{
+			Name:    "Filter overlapping boxes",
+			Objects: []refnet.Object{overlappingCoffee, coffee},
+			Params:  Params{ScaleX: 1, ScaleY: 1, ConfidenceThreshold: 0.5, NMSThreshold: 0.4},
+			Expected: []Detection{
+				{ClassID: 1, Confidence: 0.8, BoundingBox: image.Rect(20, 20, 30, 40)},
+			},
+		},
+		{
+			Name:    "Max detections",
+			Objects: []refnet.Object{coffee, laptop},
+			Params:  Params{ScaleX: 1, ScaleY: 1, ConfidenceThreshold: 0.5, NMSThreshold: 0.4, MaxDetections: 1},
+			Expected: []Detection{
+				{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)},
+			},
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			output := s.forward(refnet.New(2, test.Objects...))
+			detections, err := Decode(output, test.Params)
+			s.Require().NoError(err)
+			s.Equal(test.Expected, detections)
+		})
+	}
+}
+
+func (s *PostprocessTestSuite) TestDecodeUnexpectedOutput() {
+	tests := []struct {
+		Name   string
+		Output ml.Tensor
+	}{
+		{
+			Name:   "Incorrect amount of dimensions",
+			Output: &ml.DenseTensor{},
+		},
+		{
+			Name: "Missing data",
+			Output: func() ml.Tensor {
+				t, err := ml.NewTensor([]int{1, 2, 6}, make([]float32, 12))
+				s.Require().NoError(err)
+				return &truncatedTensor{Tensor: t}
+			}(),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := Decode(test.Output, Params{})
+			s.Error(err)
+		})
+	}
+}
+
+type truncatedTensor struct {
+	ml.Tensor
+}
+
+func (t *truncatedTensor) Data() []float32 {
+	return t.Tensor.Data()[:6]
+}
+
+func (s *PostprocessTestSuite) TestClassID() {
+	tests := []struct {
+		Name          string
+		Input         []float32
+		ExpectedIndex int
+	}{
+		{
+			Name:          "no inputs",
+			ExpectedIndex: 0,
+		},
+		{
+			Name:          "single inputs",
+			Input:         []float32{99.9},
+			ExpectedIndex: 0,
+		},
+		{
+			Name:          "highest score last",
+			Input:         []float32{70.0, 99.9},
+			ExpectedIndex: 1,
+		},
+		{
+			Name:          "highest score first",
+			Input:         []float32{99.9, 70.0},
+			ExpectedIndex: 0,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.ExpectedIndex, ClassID(test.Input))
+		})
+	}
+}
+
+func (s *PostprocessTestSuite) TestBoundingBox() {
+	tests := []struct {
+		Name         string
+		InputRow     []float32
+		ScaleX       float32
+		ScaleY       float32
+		ExpectedRect image.Rectangle
+	}{
+		{
+			Name:         "normal bounding box calculation",
+			InputRow:     []float32{2, 2, 2, 2},
+			ScaleX:       1,
+			ScaleY:       1,
+			ExpectedRect: image.Rect(1, 1, 3, 3),
+		},
+		{
+			Name:         "scaled bounding box calculation",
+			InputRow:     []float32{2, 2, 2, 2},
+			ScaleX:       2,
+			ScaleY:       3,
+			ExpectedRect: image.Rect(2, 3, 6, 9),
+		},
+		{
+			Name:         "unexpected row",
+			InputRow:     []float32{1, 1, 1},
+			ExpectedRect: image.Rect(0, 0, 0, 0),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.ExpectedRect, BoundingBox(test.InputRow, test.ScaleX, test.ScaleY))
+		})
+	}
+}
+
+func (s *PostprocessTestSuite) TestNMS() {
+	boxes := []image.Rectangle{
+		image.Rect(0, 0, 10, 10),
+		image.Rect(1, 1, 11, 11),
+		image.Rect(20, 20, 30, 30),
+	}
+	s.Equal([]int{1, 2}, NMS(boxes, []float32{0.5, 0.9, 0.7}, 0.4))
+	s.Equal([]int{1, 2, 0}, NMS(boxes, []float32{0.5, 0.9, 0.7}, 0.9))
+	s.Empty(NMS(nil, nil, 0.4))
+}
+
+func (s *PostprocessTestSuite) TestIoU() {
+	s.Equal(float32(1), IoU(image.Rect(0, 0, 10, 10), image.Rect(0, 0, 10, 10)))
+	s.Equal(float32(0), IoU(image.Rect(0, 0, 10, 10), image.Rect(20, 20, 30, 30)))
+	s.InDelta(float32(50)/float32(150), IoU(image.Rect(0, 0, 10, 10), image.Rect(5, 0, 15, 10)), 1e-6)
+	s.Equal(float32(0), IoU(image.Rectangle{}, image.Rectangle{}))
+}
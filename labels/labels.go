@@ -0,0 +1,222 @@
+// Package labels reads the class names of a model. Plain text files with one name per line,
+// Ultralytics data.yaml files and JSON arrays or maps of class index to name are supported.
+package labels
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidLabels is returned when the labels can not be parsed or are not valid.
+var ErrInvalidLabels = errors.New("invalid labels")
+
+// Format of a labels file.
+type Format int
+
+// Supported formats of labels files.
+const (
+	// Text contains one name per line, blank lines and lines starting with '#' are ignored
+	Text Format = iota
+	// YAML is an Ultralytics data.yaml file, of which the names are read from the names key
+	YAML
+	// JSON contains either an array of names or a map of class index to name
+	JSON
+)
+
+// Load reads the labels of given path. The format is determined by the extension of the
+// path, files without a known extension are parsed by sniffing their content.
+func Load(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseFormat(bytes.NewReader(content), YAML)
+	case ".json":
+		return ParseFormat(bytes.NewReader(content), JSON)
+	default:
+		return Parse(bytes.NewReader(content))
+	}
+}
+
+// Parse reads the labels from given reader, the format is determined by sniffing the content.
+func Parse(r io.Reader) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFormat(bytes.NewReader(content), Sniff(content))
+}
+
+// Sniff determines the format of the labels in given content. Content starting with an array or
+// object is considered JSON, content with a top level names key is considered YAML.
+func Sniff(content []byte) Format {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return JSON
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "names:") {
+			return YAML
+		}
+	}
+	return Text
+}
+
+// ParseFormat reads the labels of given format from given reader.
+func ParseFormat(r io.Reader, format Format) ([]string, error) {
+	var names []string
+	var err error
+	switch format {
+	case Text:
+		names, err = parseText(r)
+	case YAML:
+		names, err = parseYAML(r)
+	case JSON:
+		names, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %d", ErrInvalidLabels, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = Validate(names)
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Validate checks whether there is at least one name and whether all names are non-empty and unique.
+func Validate(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("%w: no names found", ErrInvalidLabels)
+	}
+	seen := make(map[string]int, len(names))
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("%w: name of class %d is empty", ErrInvalidLabels, i)
+		}
+		if first, ok := seen[name]; ok {
+			return fmt.Errorf("%w: name %q is used by both class %d and %d", ErrInvalidLabels, name, first, i)
+		}
+		seen[name] = i
+	}
+	return nil
+}
+
+// parseText reads one name per line, the lines are trimmed and blank lines and comments are skipped.
+func parseText(r io.Reader) ([]string, error) {
+	names := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// parseYAML reads the names key of an Ultralytics data.yaml file, which is either a list of
+// names or a map of class index to name.
+func parseYAML(r io.Reader) ([]string, error) {
+	data := struct {
+		Names yaml.Node `yaml:"names"`
+	}{}
+	err := yaml.NewDecoder(r).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLabels, err)
+	}
+
+	switch data.Names.Kind {
+	case yaml.SequenceNode:
+		names := []string{}
+		err = data.Names.Decode(&names)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLabels, err)
+		}
+		return trim(names), nil
+	case yaml.MappingNode:
+		indexed := map[int]string{}
+		err = data.Names.Decode(&indexed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLabels, err)
+		}
+		return fromIndexed(indexed)
+	default:
+		return nil, fmt.Errorf("%w: names should be a list or a map of class index to name", ErrInvalidLabels)
+	}
+}
+
+// parseJSON reads either an array of names or a map of class index to name.
+func parseJSON(r io.Reader) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if err := json.Unmarshal(content, &names); err == nil {
+		return trim(names), nil
+	}
+
+	keyed := map[string]string{}
+	err = json.Unmarshal(content, &keyed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: should be an array of names or a map of class index to name: %v", ErrInvalidLabels, err)
+	}
+	indexed := make(map[int]string, len(keyed))
+	for key, name := range keyed {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: class index %q is not a number", ErrInvalidLabels, key)
+		}
+		indexed[index] = name
+	}
+	return fromIndexed(indexed)
+}
+
+// fromIndexed converts a map of class index to name into a list, the indices should be contiguous and start at zero.
+func fromIndexed(indexed map[int]string) ([]string, error) {
+	indices := make([]int, 0, len(indexed))
+	for index := range indexed {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	names := make([]string, 0, len(indexed))
+	for i, index := range indices {
+		if index != i {
+			return nil, fmt.Errorf("%w: class %d is missing", ErrInvalidLabels, i)
+		}
+		names = append(names, strings.TrimSpace(indexed[index]))
+	}
+	return names, nil
+}
+
+// trim trims the white space of all names.
+func trim(names []string) []string {
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
@@ -0,0 +1,138 @@
+package labels
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LabelsTestSuite struct {
+	suite.Suite
+}
+
+func TestLabelsTestSuite(t *testing.T) {
+	suite.Run(t, new(LabelsTestSuite))
+}
+
+func (s *LabelsTestSuite) TestParse() {
+	tests := []struct {
+		Name     string
+		Content  string
+		Expected []string
+		Error    bool
+	}{
+		{
+			Name:     "Text with trailing newline",
+			Content:  "person\nbicycle\ncar\n",
+			Expected: []string{"person", "bicycle", "car"},
+		},
+		{
+			Name:     "Text with windows line endings",
+			Content:  "person\r\nbicycle\r\ncar\r\n",
+			Expected: []string{"person", "bicycle", "car"},
+		},
+		{
+			Name:     "Text with comments and blank lines",
+			Content:  "# coco\n  person \n\n# vehicles\nbicycle\n",
+			Expected: []string{"person", "bicycle"},
+		},
+		{
+			Name:     "Text with names containing spaces",
+			Content:  "traffic light\nfire hydrant",
+			Expected: []string{"traffic light", "fire hydrant"},
+		},
+		{
+			Name:     "YAML list",
+			Content:  "path: ../datasets/coco\nnc: 3\nnames: ['person', 'bicycle', 'car']\n",
+			Expected: []string{"person", "bicycle", "car"},
+		},
+		{
+			Name:     "YAML map",
+			Content:  "path: ../datasets/coco\nnames:\n  0: person\n  1: bicycle\n  2: traffic light\n",
+			Expected: []string{"person", "bicycle", "traffic light"},
+		},
+		{
+			Name:    "YAML map with missing class",
+			Content: "names:\n  0: person\n  2: car\n",
+			Error:   true,
+		},
+		{
+			Name:     "JSON array",
+			Content:  `["person", "bicycle", "car"]`,
+			Expected: []string{"person", "bicycle", "car"},
+		},
+		{
+			Name:     "JSON map",
+			Content:  `{"1": "bicycle", "0": "person", "2": "car"}`,
+			Expected: []string{"person", "bicycle", "car"},
+		},
+		{
+			Name:    "JSON map with invalid index",
+			Content: `{"zero": "person"}`,
+			Error:   true,
+		},
+		{
+			Name:    "Duplicate names",
+			Content: "person\ncar\nperson\n",
+			Error:   true,
+		},
+		{
+			Name:    "Empty name",
+			Content: `["person", "", "car"]`,
+			Error:   true,
+		},
+		{
+			Name:    "No names",
+			Content: "# nothing here\n",
+			Error:   true,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			names, err := Parse(strings.NewReader(test.Content))
+			if test.Error {
+				s.ErrorIs(err, ErrInvalidLabels)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Expected, names)
+		})
+	}
+}
+
+func (s *LabelsTestSuite) TestSniff() {
+	s.Equal(Text, Sniff([]byte("person\ncar\n")))
+	s.Equal(YAML, Sniff([]byte("nc: 2\nnames: [person, car]\n")))
+	s.Equal(JSON, Sniff([]byte("  [\"person\"]")))
+	s.Equal(JSON, Sniff([]byte(`{"0": "person"}`)))
+}
+
+func (s *LabelsTestSuite) TestLoad() {
+	dir := s.T().TempDir()
+	files := map[string]string{
+		"coco.names": "person\ncar\n",
+		"data.yaml":  "names:\n  - person\n  - car\n",
+		"names.json": `["person", "car"]`,
+		// The extension determines the format, even though it looks like text
+		"names.yml": "person\n",
+	}
+	for name, content := range files {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	for _, name := range []string{"coco.names", "data.yaml", "names.json"} {
+		names, err := Load(filepath.Join(dir, name))
+		s.Require().NoError(err)
+		s.Equal([]string{"person", "car"}, names)
+	}
+
+	_, err := Load(filepath.Join(dir, "names.yml"))
+	s.ErrorIs(err, ErrInvalidLabels)
+
+	_, err = Load(filepath.Join(dir, "notexistent"))
+	s.ErrorIs(err, os.ErrNotExist)
+}
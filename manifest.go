@@ -0,0 +1,44 @@
+package yolov5
+
+import (
+	"github.com/wimspaargaren/yolov5/manifest"
+)
+
+// NewNetFromManifest creates new yolo net for the model described by the manifest of given path.
+// The checksums of the model and labels are verified before the net is initialised. The input
+// size and thresholds of the manifest are used for the settings which are not set in the config.
+// Paths are relative to the manifest, so use the manifest of a pulled model such as
+// data/yolov5/manifest.json after running make models.
+func NewNetFromManifest(manifestPath string, config Config) (Net, error) {
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNetWithConfig(m.ModelPath(), m.LabelsPath(), configFromManifest(m, config))
+}
+
+// configFromManifest fills the settings which are not set in given config with the settings of the manifest.
+func configFromManifest(m *manifest.Manifest, config Config) Config {
+	if config.InputWidth == 0 {
+		config.InputWidth = m.InputWidth
+	}
+	if config.InputHeight == 0 {
+		config.InputHeight = m.InputHeight
+	}
+	if config.ConfidenceThreshold == 0 {
+		config.ConfidenceThreshold = m.ConfidenceThreshold
+	}
+	if config.NMSThreshold == 0 {
+		config.NMSThreshold = m.NMSThreshold
+	}
+	if config.ModelID == "" {
+		config.ModelID = m.ID()
+	}
+	return config
+}
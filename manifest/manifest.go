@@ -0,0 +1,197 @@
+// Package manifest describes a yolov5 model together with its labels and settings. The
+// SHA-256 checksums in the manifest are used to verify the model and labels files before
+// they are used, so a corrupted or truncated download is detected early.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidManifest is returned when a manifest is missing required fields.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// ErrUnpinned is returned when a file is verified for which the manifest does not contain a checksum.
+var ErrUnpinned = errors.New("checksum not pinned")
+
+// ChecksumMismatchError is returned when the checksum of a file does not match the manifest.
+type ChecksumMismatchError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// Error returns the error message.
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+// Manifest describes a model, the paths of the files are relative to the directory of the manifest.
+type Manifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	Model  File `json:"model"`
+	Labels File `json:"labels"`
+
+	// InputWidth & InputHeight are the input size the model was exported with
+	InputWidth  int `json:"input_width,omitempty"`
+	InputHeight int `json:"input_height,omitempty"`
+	// Recommended thresholds for the model
+	ConfidenceThreshold float32 `json:"confidence_threshold,omitempty"`
+	NMSThreshold        float32 `json:"nms_threshold,omitempty"`
+
+	dir string
+}
+
+// File a file of the model with its expected checksum. A file without checksum is considered
+// unpinned and fails verification until its checksum is pinned.
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256,omitempty"`
+	// URLs from which the file can be downloaded, in order of preference
+	URLs []string `json:"urls,omitempty"`
+}
+
+// Load reads and validates the manifest of given path.
+func Load(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.dir = filepath.Dir(path)
+	return m, nil
+}
+
+// Parse reads and validates a manifest from given reader.
+func Parse(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(m)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	return m, m.Validate()
+}
+
+// Validate ensures that the required fields of the manifest are set.
+func (m *Manifest) Validate() error {
+	switch {
+	case m.Name == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidManifest)
+	case m.Version == "":
+		return fmt.Errorf("%w: missing version", ErrInvalidManifest)
+	case m.InputWidth < 0 || m.InputHeight < 0:
+		return fmt.Errorf("%w: negative input size %dx%d", ErrInvalidManifest, m.InputWidth, m.InputHeight)
+	}
+	for name, file := range map[string]File{"model": m.Model, "labels": m.Labels} {
+		if file.Path == "" {
+			return fmt.Errorf("%w: missing %s path", ErrInvalidManifest, name)
+		}
+		if file.SHA256 != "" && !isSHA256(file.SHA256) {
+			return fmt.Errorf("%w: %s sha256 %q is not a hex encoded sha256 checksum", ErrInvalidManifest, name, file.SHA256)
+		}
+	}
+	return nil
+}
+
+// Write writes the manifest as indented JSON to given path.
+func (m *Manifest) Write(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o600)
+}
+
+// Pinned reports whether the checksums of both the model and labels are pinned.
+func (m *Manifest) Pinned() bool {
+	return m.Model.SHA256 != "" && m.Labels.SHA256 != ""
+}
+
+// ID identifies the model by its name and version.
+func (m *Manifest) ID() string {
+	return m.Name + "@" + m.Version
+}
+
+// ModelPath returns the path of the model file.
+func (m *Manifest) ModelPath() string {
+	return m.resolve(m.Model.Path)
+}
+
+// LabelsPath returns the path of the labels file.
+func (m *Manifest) LabelsPath() string {
+	return m.resolve(m.Labels.Path)
+}
+
+// Verify verifies the checksums of the model and labels files.
+func (m *Manifest) Verify() error {
+	err := VerifyFile(m.ModelPath(), m.Model.SHA256)
+	if err != nil {
+		return err
+	}
+	return VerifyFile(m.LabelsPath(), m.Labels.SHA256)
+}
+
+func (m *Manifest) resolve(path string) string {
+	if filepath.IsAbs(path) || m.dir == "" {
+		return path
+	}
+	return filepath.Join(m.dir, path)
+}
+
+// VerifyFile verifies that the file of given path has the expected SHA-256 checksum.
+func VerifyFile(path, expectedSHA256 string) error {
+	if expectedSHA256 == "" {
+		return fmt.Errorf("%s: %w", path, ErrUnpinned)
+	}
+	actual, err := FileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, expectedSHA256) {
+		return &ChecksumMismatchError{
+			Path:     path,
+			Expected: strings.ToLower(expectedSHA256),
+			Actual:   actual,
+		}
+	}
+	return nil
+}
+
+// FileSHA256 returns the hex encoded SHA-256 checksum of the file of given path.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ManifestTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestManifestTestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestTestSuite))
+}
+
+func (s *ManifestTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "yolov5s.onnx"), []byte("onnx"), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.dir, "coco.names"), []byte("person\n"), 0o600))
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *ManifestTestSuite) writeManifest(content string) string {
+	path := filepath.Join(s.dir, "manifest.json")
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (s *ManifestTestSuite) validManifest(modelSHA256 string) string {
+	return `{
+		"name": "yolov5s",
+		"version": "1.0.0",
+		"model": {"path": "yolov5s.onnx", "sha256": "` + modelSHA256 + `"},
+		"labels": {"path": "coco.names", "sha256": "` + checksum("person\n") + `"},
+		"input_width": 640,
+		"input_height": 640,
+		"confidence_threshold": 0.5,
+		"nms_threshold": 0.4
+	}`
+}
+
+func (s *ManifestTestSuite) TestLoadAndVerify() {
+	m, err := Load(s.writeManifest(s.validManifest(checksum("onnx"))))
+	s.Require().NoError(err)
+
+	s.Equal("yolov5s@1.0.0", m.ID())
+	s.Equal(filepath.Join(s.dir, "yolov5s.onnx"), m.ModelPath())
+	s.Equal(filepath.Join(s.dir, "coco.names"), m.LabelsPath())
+	s.Equal(640, m.InputWidth)
+	s.Equal(float32(0.5), m.ConfidenceThreshold)
+	s.NoError(m.Verify())
+}
+
+func (s *ManifestTestSuite) TestChecksumMismatch() {
+	m, err := Load(s.writeManifest(s.validManifest(checksum("truncated"))))
+	s.Require().NoError(err)
+
+	err = m.Verify()
+	var mismatch *ChecksumMismatchError
+	s.Require().True(errors.As(err, &mismatch))
+	s.Equal(filepath.Join(s.dir, "yolov5s.onnx"), mismatch.Path)
+	s.Equal(checksum("truncated"), mismatch.Expected)
+	s.Equal(checksum("onnx"), mismatch.Actual)
+}
+
+func (s *ManifestTestSuite) TestUnpinned() {
+	m, err := Load(s.writeManifest(s.validManifest("")))
+	s.Require().NoError(err)
+
+	s.False(m.Pinned())
+	s.True(errors.Is(m.Verify(), ErrUnpinned))
+}
+
+func (s *ManifestTestSuite) TestWrite() {
+	m, err := Load(s.writeManifest(s.validManifest(checksum("onnx"))))
+	s.Require().NoError(err)
+	m.Model.URLs = []string{"https://example.com/yolov5s.onnx"}
+
+	path := filepath.Join(s.dir, "written.json")
+	s.Require().NoError(m.Write(path))
+	written, err := Load(path)
+	s.Require().NoError(err)
+	s.Equal(m, written)
+}
+
+func (s *ManifestTestSuite) TestMissingFile() {
+	s.Require().NoError(os.Remove(filepath.Join(s.dir, "yolov5s.onnx")))
+	m, err := Load(s.writeManifest(s.validManifest(checksum("onnx"))))
+	s.Require().NoError(err)
+
+	s.True(errors.Is(m.Verify(), os.ErrNotExist))
+}
+
+func (s *ManifestTestSuite) TestInvalidManifest() {
+	tests := []struct {
+		Name     string
+		Manifest string
+	}{
+		{
+			Name:     "Malformed json",
+			Manifest: `{"name":`,
+		},
+		{
+			Name:     "Unknown field",
+			Manifest: strings.Replace(s.validManifest(checksum("onnx")), `"name"`, `"nam"`, 1),
+		},
+		{
+			Name:     "Missing name",
+			Manifest: strings.Replace(s.validManifest(checksum("onnx")), `"yolov5s"`, `""`, 1),
+		},
+		{
+			Name:     "Missing version",
+			Manifest: strings.Replace(s.validManifest(checksum("onnx")), `"1.0.0"`, `""`, 1),
+		},
+		{
+			Name:     "Missing model path",
+			Manifest: strings.Replace(s.validManifest(checksum("onnx")), `"yolov5s.onnx"`, `""`, 1),
+		},
+		{
+			Name:     "Invalid checksum",
+			Manifest: s.validManifest("abc"),
+		},
+		{
+			Name:     "Negative input size",
+			Manifest: strings.Replace(s.validManifest(checksum("onnx")), `"input_width": 640`, `"input_width": -1`, 1),
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			_, err := Parse(strings.NewReader(test.Manifest))
+			s.True(errors.Is(err, ErrInvalidManifest), err)
+		})
+	}
+}
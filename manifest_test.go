@@ -0,0 +1,121 @@
+package yolov5
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/refnet"
+	"github.com/wimspaargaren/yolov5/internal/modelstore"
+	"github.com/wimspaargaren/yolov5/manifest"
+)
+
+type ManifestTestSuite struct {
+	suite.Suite
+}
+
+func TestManifestTestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestTestSuite))
+}
+
+func (s *ManifestTestSuite) writeModel(modelContent, manifestModelContent string) string {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "model.onnx"), []byte(modelContent), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "labels.txt"), []byte("laptop\ncoffee"), 0o600))
+
+	modelSum := sha256.Sum256([]byte(manifestModelContent))
+	labelsSum := sha256.Sum256([]byte("laptop\ncoffee"))
+	content := fmt.Sprintf(`{
+		"name": "custom",
+		"version": "2",
+		"model": {"path": "model.onnx", "sha256": "%s"},
+		"labels": {"path": "labels.txt", "sha256": "%s"},
+		"input_width": 320,
+		"input_height": 320,
+		"confidence_threshold": 0.25
+	}`, hex.EncodeToString(modelSum[:]), hex.EncodeToString(labelsSum[:]))
+	path := filepath.Join(dir, "manifest.json")
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (s *ManifestTestSuite) TestNewNetFromManifest() {
+	config := Config{
+		NMSThreshold: 0.3,
+		NewNet: func(modelPath string) ml.NeuralNet {
+			s.Equal("model.onnx", filepath.Base(modelPath))
+			return refnet.New(2)
+		},
+	}
+	net, err := NewNetFromManifest(s.writeModel("onnx", "onnx"), config)
+	s.Require().NoError(err)
+
+	yoloNet := net.(*yoloNet)
+	s.Equal("custom@2", yoloNet.model.modelID)
+	s.Equal([]string{"laptop", "coffee"}, yoloNet.model.cocoNames)
+	s.Equal(320, yoloNet.DefaultInputWidth)
+	s.Equal(320, yoloNet.DefaultInputHeight)
+	s.Equal(float32(0.25), yoloNet.confidenceThreshold)
+	s.Equal(float32(0.3), yoloNet.DefaultNMSThreshold)
+}
+
+func (s *ManifestTestSuite) TestNewNetFromManifestChecksumMismatch() {
+	config := Config{
+		NewNet: func(string) ml.NeuralNet {
+			s.Fail("net should not be initialised")
+			return nil
+		},
+	}
+	_, err := NewNetFromManifest(s.writeModel("truncated", "onnx"), config)
+	var mismatch *manifest.ChecksumMismatchError
+	s.True(errors.As(err, &mismatch))
+}
+
+func (s *ManifestTestSuite) TestNewNetFromRepositoryManifest() {
+	// The manifest of the repository is used unchanged, so the test fails when it is not pinned
+	m, err := manifest.Load(filepath.Join("manifests", "yolov5.json"))
+	s.Require().NoError(err)
+	s.Require().True(m.Pinned(), "manifests/yolov5.json should pin the checksums of the model and labels")
+
+	// The files retrieved by make models are served as mirror
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("data", "yolov5"))))
+	defer server.Close()
+	store := modelstore.New(s.T().TempDir())
+	store.Client = server.Client()
+	store.Mirrors = []string{server.URL}
+	cached, err := store.Pull(context.Background(), m, false)
+	s.Require().NoError(err)
+
+	config := Config{
+		NewNet: func(modelPath string) ml.NeuralNet {
+			s.Equal(cached.ModelPath(), modelPath)
+			return refnet.New(80)
+		},
+	}
+	manifestPath := filepath.Join(filepath.Dir(cached.ModelPath()), modelstore.ManifestFile)
+	net, err := NewNetFromManifest(manifestPath, config)
+	s.Require().NoError(err)
+
+	yoloNet := net.(*yoloNet)
+	s.Equal("yolov5@main", yoloNet.model.modelID)
+	s.Len(yoloNet.model.cocoNames, 80)
+	s.Equal(640, yoloNet.DefaultInputWidth)
+	s.Equal(float32(0.5), yoloNet.confidenceThreshold)
+	s.Equal(float32(0.4), yoloNet.DefaultNMSThreshold)
+
+	// A corrupted model is rejected before the net is initialised
+	s.Require().NoError(os.WriteFile(cached.ModelPath(), []byte("onnx"), 0o600))
+	_, err = NewNetFromManifest(manifestPath, config)
+	var mismatch *manifest.ChecksumMismatchError
+	s.True(errors.As(err, &mismatch))
+}
@@ -5,76 +5,137 @@
 package mock_yolov5
 
 import (
-        reflect "reflect"
+	context "context"
+	reflect "reflect"
 
-        gomock "github.com/golang/mock/gomock"
-        yolov5 "github.com/wimspaargaren/yolov5"
-        gocv "gocv.io/x/gocv"
+	gomock "github.com/golang/mock/gomock"
+	yolov5 "github.com/wimspaargaren/yolov5"
+	gocv "gocv.io/x/gocv"
 )
 
 // MockNet is a mock of Net interface.
 type MockNet struct {
-        ctrl     *gomock.Controller
-        recorder *MockNetMockRecorder
+	ctrl     *gomock.Controller
+	recorder *MockNetMockRecorder
 }
 
 // MockNetMockRecorder is the mock recorder for MockNet.
 type MockNetMockRecorder struct {
-        mock *MockNet
+	mock *MockNet
 }
 
 // NewMockNet creates a new mock instance.
 func NewMockNet(ctrl *gomock.Controller) *MockNet {
-        mock := &MockNet{ctrl: ctrl}
-        mock.recorder = &MockNetMockRecorder{mock}
-        return mock
+	mock := &MockNet{ctrl: ctrl}
+	mock.recorder = &MockNetMockRecorder{mock}
+	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockNet) EXPECT() *MockNetMockRecorder {
-        return m.recorder
+	return m.recorder
 }
 
 // Close mocks base method.
 func (m *MockNet) Close() error {
-        m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Close")
-        ret0, _ := ret[0].(error)
-        return ret0
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Close indicates an expected call of Close.
 func (mr *MockNetMockRecorder) Close() *gomock.Call {
-        mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockNet)(nil).Close))
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockNet)(nil).Close))
+}
+
+// Detect mocks base method.
+func (m *MockNet) Detect(arg0 gocv.Mat, arg1 ...yolov5.DetectOption) ([]yolov5.ObjectDetection, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Detect", varargs...)
+	ret0, _ := ret[0].([]yolov5.ObjectDetection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Detect indicates an expected call of Detect.
+func (mr *MockNetMockRecorder) Detect(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockNet)(nil).Detect), varargs...)
+}
+
+// DetectContext mocks base method.
+func (m *MockNet) DetectContext(arg0 context.Context, arg1 gocv.Mat, arg2 ...yolov5.DetectOption) ([]yolov5.ObjectDetection, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DetectContext", varargs...)
+	ret0, _ := ret[0].([]yolov5.ObjectDetection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectContext indicates an expected call of DetectContext.
+func (mr *MockNetMockRecorder) DetectContext(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectContext", reflect.TypeOf((*MockNet)(nil).DetectContext), varargs...)
+}
+
+// DetectResult mocks base method.
+func (m *MockNet) DetectResult(arg0 context.Context, arg1 gocv.Mat, arg2 ...yolov5.DetectOption) (*yolov5.DetectionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DetectResult", varargs...)
+	ret0, _ := ret[0].(*yolov5.DetectionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectResult indicates an expected call of DetectResult.
+func (mr *MockNetMockRecorder) DetectResult(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectResult", reflect.TypeOf((*MockNet)(nil).DetectResult), varargs...)
 }
 
 // GetDetections mocks base method.
 func (m *MockNet) GetDetections(arg0 gocv.Mat) ([]yolov5.ObjectDetection, error) {
-        m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "GetDetections", arg0)
-        ret0, _ := ret[0].([]yolov5.ObjectDetection)
-        ret1, _ := ret[1].(error)
-        return ret0, ret1
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDetections", arg0)
+	ret0, _ := ret[0].([]yolov5.ObjectDetection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetDetections indicates an expected call of GetDetections.
 func (mr *MockNetMockRecorder) GetDetections(arg0 interface{}) *gomock.Call {
-        mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetections", reflect.TypeOf((*MockNet)(nil).GetDetections), arg0)
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetections", reflect.TypeOf((*MockNet)(nil).GetDetections), arg0)
 }
 
 // GetDetectionsWithFilter mocks base method.
 func (m *MockNet) GetDetectionsWithFilter(arg0 gocv.Mat, arg1 map[string]bool) ([]yolov5.ObjectDetection, error) {
-        m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "GetDetectionsWithFilter", arg0, arg1)
-        ret0, _ := ret[0].([]yolov5.ObjectDetection)
-        ret1, _ := ret[1].(error)
-        return ret0, ret1
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDetectionsWithFilter", arg0, arg1)
+	ret0, _ := ret[0].([]yolov5.ObjectDetection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetDetectionsWithFilter indicates an expected call of GetDetectionsWithFilter.
 func (mr *MockNetMockRecorder) GetDetectionsWithFilter(arg0, arg1 interface{}) *gomock.Call {
-        mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetectionsWithFilter", reflect.TypeOf((*MockNet)(nil).GetDetectionsWithFilter), arg0, arg1)
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetectionsWithFilter", reflect.TypeOf((*MockNet)(nil).GetDetectionsWithFilter), arg0, arg1)
 }
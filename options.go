@@ -0,0 +1,82 @@
+package yolov5
+
+import "image"
+
+// DetectOptions contains the settings used for a single detection call.
+// Options which are not set fall back to the settings the net was created with.
+type DetectOptions struct {
+	// ConfidenceThreshold is the minimum confidence before an object is considered to be "detected"
+	ConfidenceThreshold float32
+	// NMSThreshold is the non-maximum suppression threshold used for removing overlapping bounding boxes
+	NMSThreshold float32
+	// ClassIDsFilter contains the coco names of the classes which should be filtered out
+	ClassIDsFilter map[string]bool
+	// MaxDetections limits the amount of returned detections, zero means no limit
+	MaxDetections int
+	// ROI restricts the detection to a region of interest of the frame, an empty rectangle means the full frame
+	ROI image.Rectangle
+	// InputWidth & InputHeight are used to determine the input size of the image for the network
+	InputWidth  int
+	InputHeight int
+}
+
+// DetectOption can be used to override a setting of the net for a single detection call.
+type DetectOption func(*DetectOptions)
+
+// WithConfidenceThreshold overrides the confidence threshold.
+func WithConfidenceThreshold(threshold float32) DetectOption {
+	return func(o *DetectOptions) {
+		o.ConfidenceThreshold = threshold
+	}
+}
+
+// WithNMSThreshold overrides the non-maximum suppression threshold.
+func WithNMSThreshold(threshold float32) DetectOption {
+	return func(o *DetectOptions) {
+		o.NMSThreshold = threshold
+	}
+}
+
+// WithClassFilter filters out the detections of the given coco names.
+func WithClassFilter(classIDsFilter map[string]bool) DetectOption {
+	return func(o *DetectOptions) {
+		o.ClassIDsFilter = classIDsFilter
+	}
+}
+
+// WithMaxDetections limits the amount of returned detections to the ones with the highest confidence.
+func WithMaxDetections(max int) DetectOption {
+	return func(o *DetectOptions) {
+		o.MaxDetections = max
+	}
+}
+
+// WithROI restricts the detection to the given region of the frame. The bounding boxes
+// of the detections are still relative to the full frame.
+func WithROI(roi image.Rectangle) DetectOption {
+	return func(o *DetectOptions) {
+		o.ROI = roi
+	}
+}
+
+// WithInputSize overrides the input size of the image for the network.
+func WithInputSize(width, height int) DetectOption {
+	return func(o *DetectOptions) {
+		o.InputWidth = width
+		o.InputHeight = height
+	}
+}
+
+// detectOptions creates the options for a single detection call, starting from the settings of the net.
+func (y *yoloNet) detectOptions(opts []DetectOption) DetectOptions {
+	o := DetectOptions{
+		ConfidenceThreshold: y.confidenceThreshold,
+		NMSThreshold:        y.DefaultNMSThreshold,
+		InputWidth:          y.DefaultInputWidth,
+		InputHeight:         y.DefaultInputHeight,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gocv.io/x/gocv"
+)
+
+// imageExtensions the extensions of the files which are read by an image directory source.
+var imageExtensions = map[string]bool{
+	".bmp":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".png":  true,
+	".tif":  true,
+	".tiff": true,
+	".webp": true,
+}
+
+// ImageDirSource reads the images in a directory as frames, in the order of their file names.
+// Images are timestamped as if they were frames of a video with given frame rate.
+type ImageDirSource struct {
+	paths  []string
+	fps    float64
+	index  int
+	imread func(path string) (gocv.Mat, error)
+}
+
+// NewImageDirSource creates a source for the images in given directory, sub directories and other
+// files are skipped. Names are sorted lexically, so numbered files should be zero padded. A zero
+// frame rate defaults to DefaultFPS.
+func NewImageDirSource(dir string, fps float64) (*ImageDirSource, error) {
+	if fps == 0 {
+		fps = DefaultFPS
+	}
+	if fps < 0 {
+		return nil, fmt.Errorf("frame rate should be positive, got %v", fps)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image directory: %w", err)
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !imageExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+	return &ImageDirSource{
+		paths:  paths,
+		fps:    fps,
+		imread: readImage,
+	}, nil
+}
+
+// Len returns the amount of images in the directory.
+func (s *ImageDirSource) Len() int {
+	return len(s.paths)
+}
+
+// Read returns the next image, io.EOF is returned once all images are read.
+func (s *ImageDirSource) Read(ctx context.Context) (Frame, error) {
+	if err := ctx.Err(); err != nil {
+		return Frame{}, err
+	}
+	if s.index >= len(s.paths) {
+		return Frame{}, io.EOF
+	}
+
+	mat, err := s.imread(s.paths[s.index])
+	if err != nil {
+		return Frame{}, err
+	}
+	frame := Frame{
+		Index:     s.index,
+		Timestamp: position(s.index, s.fps),
+		Mat:       mat,
+	}
+	s.index++
+	return frame, nil
+}
+
+// readImage reads given image as 8-bit BGR.
+func readImage(path string) (gocv.Mat, error) {
+	mat := gocv.IMRead(path, gocv.IMReadColor)
+	if mat.Empty() {
+		// nolint: errcheck
+		mat.Close()
+		return gocv.Mat{}, fmt.Errorf("unable to read image %s", path)
+	}
+	return mat, nil
+}
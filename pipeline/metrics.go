@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// StageMetrics the latency of a stage of the pipeline.
+type StageMetrics struct {
+	Count int
+	Total time.Duration
+	Min   time.Duration
+	Max   time.Duration
+	// Last is the latency of the most recent frame
+	Last time.Duration
+}
+
+// Mean returns the average latency of the stage.
+func (m StageMetrics) Mean() time.Duration {
+	if m.Count == 0 {
+		return 0
+	}
+	return m.Total / time.Duration(m.Count)
+}
+
+// Metrics the latency of every stage and the amount of frames which went through the pipeline.
+type Metrics struct {
+	// Read is the time it took the source to provide a frame
+	Read StageMetrics
+	// Queue is the time frames waited for a worker
+	Queue StageMetrics
+	// Detect is the time the detector took per frame
+	Detect StageMetrics
+	// Sink is the time all sinks together took per frame
+	Sink StageMetrics
+	// Dropped is the amount of frames which were dropped because the queue was full
+	Dropped int
+}
+
+// stage identifies a stage of the pipeline.
+type stage int
+
+const (
+	stageRead stage = iota
+	stageQueue
+	stageDetect
+	stageSink
+)
+
+// metrics collects the metrics of a pipeline, it is safe for concurrent use.
+type metrics struct {
+	mu      sync.Mutex
+	current Metrics
+}
+
+func newMetrics() *metrics {
+	return &metrics{}
+}
+
+// observe records the latency of a frame in given stage.
+func (m *metrics) observe(s stage, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stage *StageMetrics
+	switch s {
+	case stageRead:
+		stage = &m.current.Read
+	case stageQueue:
+		stage = &m.current.Queue
+	case stageDetect:
+		stage = &m.current.Detect
+	default:
+		stage = &m.current.Sink
+	}
+	if stage.Count == 0 || latency < stage.Min {
+		stage.Min = latency
+	}
+	stage.Max = max(stage.Max, latency)
+	stage.Count++
+	stage.Total += latency
+	stage.Last = latency
+}
+
+// dropped records a dropped frame.
+func (m *metrics) dropped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.current.Dropped++
+}
+
+// snapshot returns a copy of the current metrics.
+func (m *metrics) snapshot() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
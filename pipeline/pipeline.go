@@ -0,0 +1,386 @@
+// Package pipeline runs detections on a stream of frames.
+//
+// A pipeline reads frames from a FrameSource into a bounded queue, runs the detector on the queued
+// frames with one or more workers and passes the results to the sinks in the order the frames
+// were read. When the detector can not keep up with the source, the drop policy decides whether
+// the source is slowed down or frames are dropped.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5"
+)
+
+// Default constants for initialising a pipeline.
+const (
+	DefaultWorkers   = 1
+	DefaultQueueSize = 2
+)
+
+// Frame a frame read from a source.
+type Frame struct {
+	// Index is the position of the frame in the source, starting at 0
+	Index int
+	// Timestamp is the moment the frame was captured, or its position in a video
+	Timestamp time.Time
+	Mat       gocv.Mat
+}
+
+// FrameSource provides the frames which are processed by a pipeline.
+type FrameSource interface {
+	// Read returns the next frame, the pipeline takes ownership of its Mat. io.EOF is returned
+	// when there are no more frames.
+	Read(ctx context.Context) (Frame, error)
+}
+
+// Detector runs detections on a frame, both yolov5.Net and *yolov5.NetPool are detectors.
+type Detector interface {
+	DetectContext(context.Context, gocv.Mat, ...yolov5.DetectOption) ([]yolov5.ObjectDetection, error)
+}
+
+// Latency the time a frame spent in the stages of the pipeline.
+type Latency struct {
+	// Queue is the time between reading the frame and the start of the detection
+	Queue  time.Duration
+	Detect time.Duration
+}
+
+// Result the detections of a single frame.
+type Result struct {
+	Frame      Frame
+	Detections []yolov5.ObjectDetection
+	// Err is the error returned by the detector, the pipeline keeps running when detection fails
+	Err error
+	// Skipped reports whether detection was skipped for the frame, see Config.DetectEvery
+	Skipped bool
+	Latency Latency
+}
+
+// Sink consumes the results of a pipeline. The Mat of the frame is closed when Consume returns,
+// sinks should clone it to keep it. Returning an error stops the pipeline.
+type Sink interface {
+	Consume(ctx context.Context, result Result) error
+}
+
+// SinkFunc allows a function to be used as sink.
+type SinkFunc func(ctx context.Context, result Result) error
+
+// Consume calls the function.
+func (f SinkFunc) Consume(ctx context.Context, result Result) error {
+	return f(ctx, result)
+}
+
+// DropPolicy determines what happens to a new frame when the queue is full.
+type DropPolicy int
+
+// Supported drop policies.
+const (
+	// Block waits until there is room in the queue, which slows down the source. This suits
+	// sources such as video files, of which every frame should be processed
+	Block DropPolicy = iota
+	// DropOldest drops the oldest queued frame, which keeps the latency low for live sources
+	DropOldest
+	// DropNewest drops the new frame
+	DropNewest
+)
+
+// String returns the name of the policy.
+func (p DropPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop_oldest"
+	case DropNewest:
+		return "drop_newest"
+	default:
+		return "unknown"
+	}
+}
+
+// Config can be used to configure a pipeline.
+type Config struct {
+	Source   FrameSource
+	Detector Detector
+	Sinks    []Sink
+	// Options are passed to the detector for every frame
+	Options []yolov5.DetectOption
+	// Workers is the amount of frames which are detected concurrently, a single yolov5.Net is not
+	// safe for concurrent use so more than one worker requires a *yolov5.NetPool
+	Workers int
+	// QueueSize is the amount of frames which can wait for a worker
+	QueueSize  int
+	DropPolicy DropPolicy
+	// DetectEvery runs the detector only on frames of which the index is a multiple of it, the
+	// other frames are passed to the sinks without detections. Zero detects every frame
+	DetectEvery int
+}
+
+// queued a frame waiting in the queue.
+type queued struct {
+	frame Frame
+	read  time.Time
+}
+
+// sequenced a result together with the order in which its frame was taken from the queue.
+type sequenced struct {
+	seq    int
+	result Result
+}
+
+// Pipeline streams frames from a source through a detector into sinks.
+type Pipeline struct {
+	config   Config
+	metrics  *metrics
+	closeMat func(*gocv.Mat) error
+
+	// dequeue serialises taking frames from the queue, so the sequence numbers follow the queue order
+	dequeue sync.Mutex
+	nextSeq int
+	running sync.Mutex
+}
+
+// New creates a pipeline for given config, zero values are replaced with their defaults.
+func New(config Config) (*Pipeline, error) {
+	if config.Source == nil {
+		return nil, fmt.Errorf("pipeline requires a frame source")
+	}
+	if config.Detector == nil {
+		return nil, fmt.Errorf("pipeline requires a detector")
+	}
+	if config.Workers == 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Workers < 0 {
+		return nil, fmt.Errorf("workers should be at least 1, got %d", config.Workers)
+	}
+	if config.QueueSize < 0 {
+		return nil, fmt.Errorf("queue size should be at least 1, got %d", config.QueueSize)
+	}
+	if config.DetectEvery < 0 {
+		return nil, fmt.Errorf("detect every should not be negative, got %d", config.DetectEvery)
+	}
+	if config.DropPolicy < Block || config.DropPolicy > DropNewest {
+		return nil, fmt.Errorf("unknown drop policy %d", config.DropPolicy)
+	}
+	return &Pipeline{
+		config:   config,
+		metrics:  newMetrics(),
+		closeMat: func(m *gocv.Mat) error { return m.Close() },
+	}, nil
+}
+
+// Run processes frames until the source returns io.EOF, the context is done or a sink fails.
+// When the source is exhausted all queued frames are processed and nil is returned. Otherwise
+// the frames which were not yet passed to the sinks are discarded and the error is returned.
+// Run returns once all goroutines have stopped and all frames are closed.
+func (p *Pipeline) Run(ctx context.Context) error {
+	if !p.running.TryLock() {
+		return fmt.Errorf("pipeline is already running")
+	}
+	defer p.running.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan queued, p.config.QueueSize)
+	results := make(chan sequenced, p.config.Workers)
+	p.nextSeq = 0
+
+	var sourceErr error
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		defer close(queue)
+		sourceErr = p.read(ctx, queue)
+		if sourceErr != nil {
+			cancel()
+		}
+	}()
+
+	workers := sync.WaitGroup{}
+	for i := 0; i < p.config.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			p.detect(ctx, queue, results)
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	sinkErr := p.consume(ctx, cancel, results)
+	<-readDone
+
+	switch {
+	case sinkErr != nil:
+		return sinkErr
+	case sourceErr != nil:
+		return sourceErr
+	default:
+		return ctx.Err()
+	}
+}
+
+// read reads frames from the source into the queue until the source is exhausted.
+func (p *Pipeline) read(ctx context.Context, queue chan queued) error {
+	for {
+		start := time.Now()
+		frame, err := p.config.Source.Read(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			if err == nil {
+				p.close(frame)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read frame: %w", err)
+		}
+		read := time.Now()
+		p.metrics.observe(stageRead, read.Sub(start))
+
+		item := queued{frame: frame, read: read}
+		switch p.config.DropPolicy {
+		case DropOldest:
+			select {
+			case queue <- item:
+			default:
+				select {
+				case old := <-queue:
+					p.drop(old.frame)
+				default:
+				}
+				// This goroutine is the only sender, so there is room after dropping a frame
+				queue <- item
+			}
+		case DropNewest:
+			select {
+			case queue <- item:
+			default:
+				p.drop(frame)
+			}
+		default:
+			select {
+			case queue <- item:
+			case <-ctx.Done():
+				p.close(frame)
+				return nil
+			}
+		}
+	}
+}
+
+// detect runs the detector on the queued frames until the queue is closed.
+func (p *Pipeline) detect(ctx context.Context, queue chan queued, results chan sequenced) {
+	for {
+		p.dequeue.Lock()
+		item, ok := <-queue
+		seq := p.nextSeq
+		p.nextSeq++
+		p.dequeue.Unlock()
+		if !ok {
+			return
+		}
+		if ctx.Err() != nil {
+			p.close(item.frame)
+			continue
+		}
+
+		if p.config.DetectEvery > 1 && item.frame.Index%p.config.DetectEvery != 0 {
+			results <- sequenced{seq: seq, result: Result{Frame: item.frame, Skipped: true}}
+			continue
+		}
+
+		start := time.Now()
+		detections, err := p.config.Detector.DetectContext(ctx, item.frame.Mat, p.config.Options...)
+		latency := Latency{Queue: start.Sub(item.read), Detect: time.Since(start)}
+		p.metrics.observe(stageQueue, latency.Queue)
+		p.metrics.observe(stageDetect, latency.Detect)
+
+		results <- sequenced{seq: seq, result: Result{
+			Frame:      item.frame,
+			Detections: detections,
+			Err:        err,
+			Latency:    latency,
+		}}
+	}
+}
+
+// consume passes the results to the sinks in the order their frames were taken from the queue.
+// When a sink fails the pipeline is cancelled and the remaining results are discarded.
+func (p *Pipeline) consume(ctx context.Context, cancel context.CancelFunc, results chan sequenced) error {
+	var sinkErr error
+	pending := map[int]Result{}
+	next := 0
+	for r := range results {
+		if sinkErr != nil || ctx.Err() != nil {
+			p.close(r.result.Frame)
+			continue
+		}
+		pending[r.seq] = r.result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if sinkErr == nil && ctx.Err() == nil {
+				if sinkErr = p.sink(ctx, result); sinkErr != nil {
+					cancel()
+				}
+			}
+			p.close(result.Frame)
+		}
+	}
+	for _, result := range pending {
+		p.close(result.Frame)
+	}
+	return sinkErr
+}
+
+// sink passes a result to all sinks.
+func (p *Pipeline) sink(ctx context.Context, result Result) error {
+	start := time.Now()
+	defer func() {
+		p.metrics.observe(stageSink, time.Since(start))
+	}()
+	for _, sink := range p.config.Sinks {
+		if err := sink.Consume(ctx, result); err != nil {
+			return fmt.Errorf("sink failed on frame %d: %w", result.Frame.Index, err)
+		}
+	}
+	return nil
+}
+
+// drop discards a frame which did not fit in the queue.
+func (p *Pipeline) drop(frame Frame) {
+	p.metrics.dropped()
+	p.close(frame)
+}
+
+func (p *Pipeline) close(frame Frame) {
+	// nolint: errcheck
+	p.closeMat(&frame.Mat)
+}
+
+// Metrics returns the metrics of the pipeline, the metrics are kept across runs.
+func (p *Pipeline) Metrics() Metrics {
+	return p.metrics.snapshot()
+}
@@ -0,0 +1,351 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5"
+)
+
+type PipelineTestSuite struct {
+	suite.Suite
+}
+
+func TestPipelineTestSuite(t *testing.T) {
+	suite.Run(t, new(PipelineTestSuite))
+}
+
+// fakeSource returns frames until the limit is reached, a negative limit never ends.
+type fakeSource struct {
+	limit int
+	read  int
+	err   error
+	// wait is called before every frame is returned
+	wait func(index int)
+	// done is called when the source is exhausted
+	done func()
+}
+
+func (s *fakeSource) Read(ctx context.Context) (Frame, error) {
+	if err := ctx.Err(); err != nil {
+		return Frame{}, err
+	}
+	if s.limit >= 0 && s.read >= s.limit {
+		if s.done != nil {
+			s.done()
+		}
+		if s.err != nil {
+			return Frame{}, s.err
+		}
+		return Frame{}, io.EOF
+	}
+	if s.wait != nil {
+		s.wait(s.read)
+	}
+	frame := Frame{Index: s.read, Timestamp: time.Unix(int64(s.read), 0)}
+	s.read++
+	return frame, nil
+}
+
+// fakeDetector calls detect for every frame.
+type fakeDetector struct {
+	calls  atomic.Int32
+	detect func(call int) ([]yolov5.ObjectDetection, error)
+}
+
+func (d *fakeDetector) DetectContext(_ context.Context, _ gocv.Mat, _ ...yolov5.DetectOption) ([]yolov5.ObjectDetection, error) {
+	call := int(d.calls.Add(1)) - 1
+	if d.detect == nil {
+		return []yolov5.ObjectDetection{}, nil
+	}
+	return d.detect(call)
+}
+
+// collector a sink which records the indices of the frames it consumed.
+type collector struct {
+	mu      sync.Mutex
+	indices []int
+	results []Result
+}
+
+func (c *collector) Consume(_ context.Context, result Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indices = append(c.indices, result.Frame.Index)
+	c.results = append(c.results, result)
+	return nil
+}
+
+// newPipeline creates a pipeline which counts the closed frames instead of closing them.
+func (s *PipelineTestSuite) newPipeline(config Config, closed *atomic.Int32) *Pipeline {
+	p, err := New(config)
+	s.Require().NoError(err)
+	p.closeMat = func(*gocv.Mat) error {
+		closed.Add(1)
+		return nil
+	}
+	return p
+}
+
+func (s *PipelineTestSuite) TestNewInvalidConfig() {
+	source, detector := &fakeSource{}, &fakeDetector{}
+	_, err := New(Config{Detector: detector})
+	s.Error(err)
+	_, err = New(Config{Source: source})
+	s.Error(err)
+	_, err = New(Config{Source: source, Detector: detector, Workers: -1})
+	s.Error(err)
+	_, err = New(Config{Source: source, Detector: detector, QueueSize: -1})
+	s.Error(err)
+	_, err = New(Config{Source: source, Detector: detector, DropPolicy: DropPolicy(5)})
+	s.Error(err)
+	_, err = New(Config{Source: source, Detector: detector, DetectEvery: -1})
+	s.Error(err)
+}
+
+func (s *PipelineTestSuite) TestOrderedOutput() {
+	source := &fakeSource{limit: 50}
+	detector := &fakeDetector{detect: func(call int) ([]yolov5.ObjectDetection, error) {
+		// Later frames regularly finish before earlier ones
+		time.Sleep(time.Duration(3-call%4) * time.Millisecond)
+		return []yolov5.ObjectDetection{{ClassName: "person"}}, nil
+	}}
+	sink := &collector{}
+	closed := &atomic.Int32{}
+	p := s.newPipeline(Config{Source: source, Detector: detector, Sinks: []Sink{sink}, Workers: 4}, closed)
+
+	s.Require().NoError(p.Run(context.Background()))
+	expected := make([]int, 50)
+	for i := range expected {
+		expected[i] = i
+	}
+	s.Equal(expected, sink.indices)
+	s.Len(sink.results[0].Detections, 1)
+	s.Equal(int32(50), closed.Load())
+
+	metrics := p.Metrics()
+	s.Equal(50, metrics.Read.Count)
+	s.Equal(50, metrics.Detect.Count)
+	s.Equal(50, metrics.Sink.Count)
+	s.Equal(0, metrics.Dropped)
+	s.GreaterOrEqual(metrics.Detect.Max, metrics.Detect.Mean())
+	s.LessOrEqual(metrics.Detect.Min, metrics.Detect.Mean())
+}
+
+func (s *PipelineTestSuite) TestDropPolicies() {
+	tests := []struct {
+		Name     string
+		Policy   DropPolicy
+		Expected []int
+	}{
+		{Name: "Drop oldest", Policy: DropOldest, Expected: []int{0, 4}},
+		{Name: "Drop newest", Policy: DropNewest, Expected: []int{0, 1}},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			// The first frame occupies the worker until the source is exhausted, the queue fits one frame
+			entered, release := make(chan struct{}), make(chan struct{})
+			source := &fakeSource{
+				limit: 5,
+				wait: func(index int) {
+					if index == 1 {
+						<-entered
+					}
+				},
+				done: func() { close(release) },
+			}
+			detector := &fakeDetector{detect: func(call int) ([]yolov5.ObjectDetection, error) {
+				if call == 0 {
+					close(entered)
+					<-release
+				}
+				return nil, nil
+			}}
+			sink := &collector{}
+			closed := &atomic.Int32{}
+			p := s.newPipeline(Config{Source: source, Detector: detector, Sinks: []Sink{sink}, QueueSize: 1, DropPolicy: test.Policy}, closed)
+
+			s.Require().NoError(p.Run(context.Background()))
+			s.Equal(test.Expected, sink.indices)
+			s.Equal(3, p.Metrics().Dropped)
+			s.Equal(int32(5), closed.Load())
+		})
+	}
+}
+
+func (s *PipelineTestSuite) TestDetectionErrors() {
+	errDetect := errors.New("detection failed")
+	source := &fakeSource{limit: 3}
+	detector := &fakeDetector{detect: func(call int) ([]yolov5.ObjectDetection, error) {
+		if call == 1 {
+			return nil, errDetect
+		}
+		return nil, nil
+	}}
+	sink := &collector{}
+	p := s.newPipeline(Config{Source: source, Detector: detector, Sinks: []Sink{sink}}, &atomic.Int32{})
+
+	s.Require().NoError(p.Run(context.Background()))
+	s.Require().Len(sink.results, 3)
+	s.NoError(sink.results[0].Err)
+	s.ErrorIs(sink.results[1].Err, errDetect)
+	s.NoError(sink.results[2].Err)
+}
+
+func (s *PipelineTestSuite) TestDetectEvery() {
+	detector := &fakeDetector{}
+	sink := &collector{}
+	p := s.newPipeline(Config{Source: &fakeSource{limit: 7}, Detector: detector, Sinks: []Sink{sink}, DetectEvery: 3}, &atomic.Int32{})
+
+	s.Require().NoError(p.Run(context.Background()))
+	s.Equal(int32(3), detector.calls.Load())
+	s.Require().Len(sink.results, 7)
+	for i, result := range sink.results {
+		s.Equal(i%3 != 0, result.Skipped)
+	}
+	s.Equal(3, p.Metrics().Detect.Count)
+}
+
+func (s *PipelineTestSuite) TestSourceError() {
+	errSource := errors.New("camera disconnected")
+	closed := &atomic.Int32{}
+	p := s.newPipeline(Config{Source: &fakeSource{limit: 3, err: errSource}, Detector: &fakeDetector{}, Workers: 2}, closed)
+	s.ErrorIs(p.Run(context.Background()), errSource)
+	s.Equal(int32(3), closed.Load())
+}
+
+func (s *PipelineTestSuite) TestSinkError() {
+	errSink := errors.New("disk full")
+	source := &fakeSource{limit: -1}
+	sink := SinkFunc(func(_ context.Context, result Result) error {
+		if result.Frame.Index == 3 {
+			return errSink
+		}
+		return nil
+	})
+	closed := &atomic.Int32{}
+	p := s.newPipeline(Config{Source: source, Detector: &fakeDetector{}, Sinks: []Sink{sink}, Workers: 3}, closed)
+
+	s.ErrorIs(p.Run(context.Background()), errSink)
+	s.Equal(int32(source.read), closed.Load())
+}
+
+func (s *PipelineTestSuite) TestCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &fakeSource{limit: -1}
+	sink := SinkFunc(func(_ context.Context, result Result) error {
+		if result.Frame.Index >= 5 {
+			cancel()
+		}
+		return nil
+	})
+	closed := &atomic.Int32{}
+	p := s.newPipeline(Config{Source: source, Detector: &fakeDetector{}, Sinks: []Sink{sink}, Workers: 2, DropPolicy: DropOldest}, closed)
+
+	s.ErrorIs(p.Run(ctx), context.Canceled)
+	s.Equal(int32(source.read), closed.Load())
+}
+
+func (s *PipelineTestSuite) TestDropPolicyString() {
+	s.Equal("block", Block.String())
+	s.Equal("drop_oldest", DropOldest.String())
+	s.Equal("drop_newest", DropNewest.String())
+}
+
+// fakeWriter records the amount of written frames.
+type fakeWriter struct {
+	written int
+	closed  bool
+}
+
+func (w *fakeWriter) Write(gocv.Mat) error {
+	w.written++
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func (s *PipelineTestSuite) TestVideoWriterSink() {
+	writer := &fakeWriter{}
+	var opened []interface{}
+	open := func(path, codec string, fps float64, size image.Point) (frameWriter, error) {
+		opened = []interface{}{path, codec, fps, size}
+		return writer, nil
+	}
+	sink, err := newVideoWriterSink("out.mp4", 30, image.Pt(640, 480), "", open)
+	s.Require().NoError(err)
+	s.Equal([]interface{}{"out.mp4", DefaultCodec, 30.0, image.Pt(640, 480)}, opened)
+	drawn := [][]yolov5.ObjectDetection{}
+	sink.draw = func(_ *gocv.Mat, detections []yolov5.ObjectDetection) {
+		drawn = append(drawn, detections)
+	}
+
+	person := []yolov5.ObjectDetection{{ClassName: "person"}}
+	s.Require().NoError(sink.Consume(context.Background(), Result{Detections: person}))
+	// Skipped frames are annotated with the last detections
+	s.Require().NoError(sink.Consume(context.Background(), Result{Skipped: true}))
+	s.Require().NoError(sink.Consume(context.Background(), Result{Detections: []yolov5.ObjectDetection{}}))
+	s.Equal([][]yolov5.ObjectDetection{person, person, {}}, drawn)
+	s.Equal(3, writer.written)
+	s.NoError(sink.Close())
+	s.True(writer.closed)
+
+	_, err = newVideoWriterSink("out.mp4", 0, image.Pt(640, 480), "", open)
+	s.Error(err)
+	_, err = newVideoWriterSink("out.mp4", 30, image.Point{}, "", open)
+	s.Error(err)
+	_, err = newVideoWriterSink("out.mp4", 30, image.Pt(640, 480), "h264x", open)
+	s.Error(err)
+}
+
+func (s *PipelineTestSuite) TestJSONLSink() {
+	buf := &bytes.Buffer{}
+	sink := NewJSONLSink(buf)
+	s.Require().NoError(sink.Consume(context.Background(), Result{
+		Frame: Frame{Index: 2, Timestamp: epoch.Add(80 * time.Millisecond)},
+		Detections: []yolov5.ObjectDetection{
+			{ClassID: 0, ClassName: "person", Confidence: 0.5, BoundingBox: image.Rect(1, 2, 3, 4)},
+		},
+	}))
+	s.Require().NoError(sink.Consume(context.Background(), Result{Frame: Frame{Index: 3}, Skipped: true}))
+	s.Require().NoError(sink.Consume(context.Background(), Result{Frame: Frame{Index: 4}, Err: errors.New("empty frame")}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.Require().Len(lines, 3)
+	s.JSONEq(`{"frame":2,"timestamp_ms":80,"detections":[{"class_id":0,"class_name":"person","confidence":0.5,"box":[1,2,3,4]}]}`, lines[0])
+	s.Contains(lines[1], `"skipped":true`)
+	s.Contains(lines[2], `"error":"empty frame"`)
+}
+
+func (s *PipelineTestSuite) TestProgress() {
+	progress := []Progress{}
+	sink := progressSink(4, func(p Progress) {
+		progress = append(progress, p)
+	})
+	for i := 0; i < 2; i++ {
+		s.Require().NoError(sink.Consume(context.Background(), Result{}))
+	}
+	s.Require().Len(progress, 2)
+	s.Equal(2, progress[1].Frames)
+	s.InDelta(0.5, progress[1].Fraction(), 1e-9)
+
+	s.Equal(0.0, Progress{Frames: 3}.Fraction())
+	s.InDelta(10, Progress{Frames: 20, Elapsed: 2 * time.Second}.FPS(), 1e-9)
+	s.Equal(0.0, Progress{}.FPS())
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/wimspaargaren/yolov5"
+)
+
+// Progress the progress of processing a video.
+type Progress struct {
+	// Frames is the amount of frames which are processed
+	Frames int
+	// Total is the amount of frames in the video as reported by the video, zero when unknown
+	Total   int
+	Elapsed time.Duration
+}
+
+// Fraction returns the fraction of the video which is processed, zero when the total is unknown.
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(float64(p.Frames)/float64(p.Total), 1)
+}
+
+// FPS returns the average amount of frames processed per second.
+func (p Progress) FPS() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Frames) / p.Elapsed.Seconds()
+}
+
+// VideoProcessConfig can be used to configure the processing of a video file.
+type VideoProcessConfig struct {
+	// Input & Output are the paths of the source video and the annotated copy
+	Input  string
+	Output string
+	// Codec is the four character code of the codec of the output, it defaults to DefaultCodec
+	Codec string
+	// DetectEvery runs the detector on every Nth frame only, the other frames are annotated with the
+	// last detections. Zero detects every frame
+	DetectEvery int
+	// Workers is the amount of frames which are detected concurrently, see Config.Workers
+	Workers int
+	Options []yolov5.DetectOption
+	// Log receives a line of JSON with the detections of every frame when set, see JSONLSink
+	Log io.Writer
+	// Progress is called after every processed frame when set
+	Progress func(Progress)
+}
+
+// ProcessVideo runs the detector over a video file and writes an annotated copy with the frame
+// rate and frame size of the source. It returns the metrics of the pipeline once every frame
+// is written.
+func ProcessVideo(ctx context.Context, detector Detector, config VideoProcessConfig) (Metrics, error) {
+	source, err := NewVideoFileSource(config.Input)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("unable to open input video: %w", err)
+	}
+	defer func() {
+		// nolint: errcheck
+		source.Close()
+	}()
+
+	writer, err := NewVideoWriterSink(config.Output, source.FPS(), source.FrameSize(), config.Codec)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("unable to create output video: %w", err)
+	}
+
+	sinks := []Sink{writer}
+	if config.Log != nil {
+		sinks = append(sinks, NewJSONLSink(config.Log))
+	}
+	if config.Progress != nil {
+		sinks = append(sinks, progressSink(source.FrameCount(), config.Progress))
+	}
+
+	p, err := New(Config{
+		Source:      source,
+		Detector:    detector,
+		Sinks:       sinks,
+		Options:     config.Options,
+		Workers:     config.Workers,
+		DetectEvery: config.DetectEvery,
+	})
+	if err != nil {
+		// nolint: errcheck
+		writer.Close()
+		return Metrics{}, err
+	}
+
+	err = p.Run(ctx)
+	if closeErr := writer.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("unable to finish output video: %w", closeErr)
+	}
+	return p.Metrics(), err
+}
+
+// progressSink returns a sink which reports the progress after every frame.
+func progressSink(total int, report func(Progress)) Sink {
+	start := time.Now()
+	frames := 0
+	return SinkFunc(func(context.Context, Result) error {
+		frames++
+		report(Progress{Frames: frames, Total: total, Elapsed: time.Since(start)})
+		return nil
+	})
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+
+	"gocv.io/x/gocv"
+
+	"github.com/wimspaargaren/yolov5"
+)
+
+// DefaultCodec is the default four character code of the codec used to write videos.
+const DefaultCodec = "mp4v"
+
+// frameWriter writes frames to a video.
+type frameWriter interface {
+	Write(gocv.Mat) error
+	Close() error
+}
+
+func openVideoWriter(path, codec string, fps float64, size image.Point) (frameWriter, error) {
+	vw, err := gocv.VideoWriterFile(path, codec, fps, size.X, size.Y, true)
+	if err != nil {
+		return nil, err
+	}
+	if !vw.IsOpened() {
+		// nolint: errcheck
+		vw.Close()
+		return nil, fmt.Errorf("unable to open video writer %s", path)
+	}
+	return vw, nil
+}
+
+// VideoWriterSink draws the detections on the frames and writes them to a video file. Frames
+// for which the detection was skipped are annotated with the detections of the last detected frame.
+type VideoWriterSink struct {
+	writer frameWriter
+	last   []yolov5.ObjectDetection
+	draw   func(*gocv.Mat, []yolov5.ObjectDetection)
+}
+
+// NewVideoWriterSink creates a video file with given frame rate and frame size, which should
+// match the size of the frames of the source. An empty codec defaults to DefaultCodec.
+func NewVideoWriterSink(path string, fps float64, size image.Point, codec string) (*VideoWriterSink, error) {
+	return newVideoWriterSink(path, fps, size, codec, openVideoWriter)
+}
+
+func newVideoWriterSink(path string, fps float64, size image.Point, codec string, open func(string, string, float64, image.Point) (frameWriter, error)) (*VideoWriterSink, error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("frame rate should be positive, got %v", fps)
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("frame size should be positive, got %v", size)
+	}
+	if codec == "" {
+		codec = DefaultCodec
+	}
+	if len(codec) != 4 {
+		return nil, fmt.Errorf("codec should be a four character code, got %q", codec)
+	}
+	writer, err := open(path, codec, fps, size)
+	if err != nil {
+		return nil, err
+	}
+	return &VideoWriterSink{
+		writer: writer,
+		draw:   yolov5.DrawDetections,
+	}, nil
+}
+
+// Consume annotates the frame and writes it to the video.
+func (s *VideoWriterSink) Consume(_ context.Context, result Result) error {
+	if !result.Skipped {
+		s.last = result.Detections
+	}
+	s.draw(&result.Frame.Mat, s.last)
+	if err := s.writer.Write(result.Frame.Mat); err != nil {
+		return fmt.Errorf("unable to write frame %d: %w", result.Frame.Index, err)
+	}
+	return nil
+}
+
+// Close finishes the video.
+func (s *VideoWriterSink) Close() error {
+	return s.writer.Close()
+}
+
+// jsonDetection the JSON representation of a detection.
+type jsonDetection struct {
+	ClassID            int     `json:"class_id"`
+	ClassName          string  `json:"class_name"`
+	Confidence         float32 `json:"confidence"`
+	Box                [4]int  `json:"box"`
+	SubLabel           string  `json:"sub_label,omitempty"`
+	SubLabelConfidence float32 `json:"sub_label_confidence,omitempty"`
+}
+
+// jsonFrame the JSON representation of the result of a frame.
+type jsonFrame struct {
+	Frame int `json:"frame"`
+	// TimestampMS is the Unix timestamp in milliseconds, for video files this is the position in the video
+	TimestampMS int64           `json:"timestamp_ms"`
+	Skipped     bool            `json:"skipped,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Detections  []jsonDetection `json:"detections"`
+}
+
+// JSONLSink logs the detections of every frame as a line of JSON. Boxes are written as
+// [min x, min y, max x, max y] and timestamps as Unix milliseconds, which is the position
+// in the video for video files.
+type JSONLSink struct {
+	encoder *json.Encoder
+}
+
+// NewJSONLSink creates a sink which writes to given writer.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{encoder: json.NewEncoder(w)}
+}
+
+// Consume writes the detections of a frame.
+func (s *JSONLSink) Consume(_ context.Context, result Result) error {
+	frame := jsonFrame{
+		Frame:       result.Frame.Index,
+		TimestampMS: result.Frame.Timestamp.UnixMilli(),
+		Skipped:     result.Skipped,
+		Detections:  make([]jsonDetection, 0, len(result.Detections)),
+	}
+	if result.Err != nil {
+		frame.Error = result.Err.Error()
+	}
+	for _, d := range result.Detections {
+		frame.Detections = append(frame.Detections, jsonDetection{
+			ClassID:            d.ClassID,
+			ClassName:          d.ClassName,
+			Confidence:         d.Confidence,
+			Box:                [4]int{d.BoundingBox.Min.X, d.BoundingBox.Min.Y, d.BoundingBox.Max.X, d.BoundingBox.Max.Y},
+			SubLabel:           d.SubLabel,
+			SubLabelConfidence: d.SubLabelConfidence,
+		})
+	}
+	if err := s.encoder.Encode(frame); err != nil {
+		return fmt.Errorf("unable to write detection log: %w", err)
+	}
+	return nil
+}
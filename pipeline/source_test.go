@@ -0,0 +1,252 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// fakeCapture returns the given amount of empty frames and frames before failing.
+type fakeCapture struct {
+	empty  int
+	frames int
+	fps    float64
+	closed bool
+}
+
+func (c *fakeCapture) read() (gocv.Mat, error) {
+	if c.empty > 0 {
+		c.empty--
+		return gocv.Mat{}, errEmptyFrame
+	}
+	if c.frames == 0 {
+		return gocv.Mat{}, io.EOF
+	}
+	c.frames--
+	return gocv.Mat{}, nil
+}
+
+func (c *fakeCapture) get(prop gocv.VideoCaptureProperties) float64 {
+	switch prop {
+	case gocv.VideoCaptureFPS:
+		return c.fps
+	case gocv.VideoCaptureFrameWidth:
+		return 640
+	case gocv.VideoCaptureFrameHeight:
+		return 480
+	case gocv.VideoCaptureFrameCount:
+		return float64(c.frames)
+	default:
+		return 0
+	}
+}
+
+func (c *fakeCapture) Close() error {
+	c.closed = true
+	return nil
+}
+
+// opener opens the given captures in order, nil entries fail to open.
+type opener struct {
+	captures []*fakeCapture
+	opened   int
+}
+
+func (o *opener) open(interface{}) (capture, error) {
+	if o.opened >= len(o.captures) {
+		return nil, errors.New("no device")
+	}
+	c := o.captures[o.opened]
+	o.opened++
+	if c == nil {
+		return nil, errors.New("device busy")
+	}
+	return c, nil
+}
+
+// readAll reads frames until an error occurs.
+func readAll(source FrameSource) ([]Frame, error) {
+	frames := []Frame{}
+	for {
+		frame, err := source.Read(context.Background())
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+func (s *PipelineTestSuite) TestVideoFileSource() {
+	o := &opener{captures: []*fakeCapture{{frames: 3, fps: 10}}}
+	source, err := newVideoSource("video.mp4", VideoConfig{}, o.open)
+	s.Require().NoError(err)
+	s.Equal(10.0, source.FPS())
+	s.Equal(image.Pt(640, 480), source.FrameSize())
+	s.Equal(3, source.FrameCount())
+
+	frames, err := readAll(source)
+	s.ErrorIs(err, io.EOF)
+	s.Require().Len(frames, 3)
+	for i, frame := range frames {
+		s.Equal(i, frame.Index)
+		s.Equal(epoch.Add(time.Duration(i)*100*time.Millisecond), frame.Timestamp)
+	}
+	s.Equal(1, o.opened)
+
+	s.NoError(source.Close())
+	s.True(o.captures[0].closed)
+	_, err = source.Read(context.Background())
+	s.ErrorIs(err, io.EOF)
+
+	_, err = newVideoSource("missing.mp4", VideoConfig{}, (&opener{}).open)
+	s.Error(err)
+	_, err = newVideoSource("video.mp4", VideoConfig{Backoff: time.Minute, MaxBackoff: time.Second}, o.open)
+	s.Error(err)
+}
+
+func (s *PipelineTestSuite) TestCaptureDeviceReconnect() {
+	// The device disconnects after 2 frames, the first reconnect attempt fails
+	first, second := &fakeCapture{frames: 2}, &fakeCapture{frames: 1}
+	o := &opener{captures: []*fakeCapture{first, nil, second}}
+	source, err := newVideoSource(0, VideoConfig{Live: true, MaxReconnects: 2, Backoff: time.Second, MaxBackoff: 3 * time.Second}, o.open)
+	s.Require().NoError(err)
+	s.Equal(DefaultFPS, source.FPS())
+	waits := []time.Duration{}
+	source.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	frames, err := readAll(source)
+	s.Require().Len(frames, 3)
+	s.Equal(2, frames[2].Index)
+	s.True(first.closed)
+	// After the last frame all attempts fail, the backoff doubles up to the maximum
+	s.Error(err)
+	s.Equal([]time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second}, waits)
+
+	// Waiting for a reconnect respects the context
+	source.sleep = sleep
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = source.Read(ctx)
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *PipelineTestSuite) TestCaptureDeviceEmptyFrames() {
+	// The device returns empty frames while warming up
+	o := &opener{captures: []*fakeCapture{{empty: 3, frames: 1}, {empty: 3, frames: 1}}}
+	source, err := newVideoSource(0, VideoConfig{Live: true, MaxReconnects: 1}, o.open)
+	s.Require().NoError(err)
+	source.sleep = func(context.Context, time.Duration) error {
+		return nil
+	}
+
+	frame, err := source.Read(context.Background())
+	s.Require().NoError(err)
+	s.Equal(0, frame.Index)
+	s.Equal(1, o.opened)
+
+	// Too many empty frames are considered a failed read
+	source.config.MaxEmptyFrames = 2
+	_, err = source.Read(context.Background())
+	s.Error(err)
+	s.Equal(2, o.opened)
+}
+
+func (s *PipelineTestSuite) TestCloseWhileReconnecting() {
+	o := &opener{captures: []*fakeCapture{{}}}
+	source, err := newVideoSource(0, VideoConfig{Live: true, MaxReconnects: -1}, o.open)
+	s.Require().NoError(err)
+	// The source can be queried and closed while it waits for a reconnect
+	source.sleep = func(context.Context, time.Duration) error {
+		s.Equal(DefaultFPS, source.FPS())
+		return source.Close()
+	}
+
+	_, err = source.Read(context.Background())
+	s.ErrorIs(err, io.EOF)
+	s.Equal(1, o.opened)
+}
+
+func (s *PipelineTestSuite) TestImageDirSource() {
+	dir := s.T().TempDir()
+	for _, name := range []string{"frame_010.jpg", "frame_002.PNG", "frame_001.jpg", "notes.txt"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte{}, 0o600))
+	}
+	s.Require().NoError(os.Mkdir(filepath.Join(dir, "frame_000.jpg"), 0o700))
+
+	source, err := NewImageDirSource(dir, 2)
+	s.Require().NoError(err)
+	s.Equal(3, source.Len())
+	paths := []string{}
+	source.imread = func(path string) (gocv.Mat, error) {
+		paths = append(paths, filepath.Base(path))
+		return gocv.Mat{}, nil
+	}
+
+	frames, err := readAll(source)
+	s.ErrorIs(err, io.EOF)
+	s.Equal([]string{"frame_001.jpg", "frame_002.PNG", "frame_010.jpg"}, paths)
+	s.Require().Len(frames, 3)
+	s.Equal(2, frames[2].Index)
+	s.Equal(epoch.Add(time.Second), frames[2].Timestamp)
+
+	_, err = NewImageDirSource(filepath.Join(dir, "missing"), 0)
+	s.Error(err)
+	_, err = NewImageDirSource(dir, -1)
+	s.Error(err)
+}
+
+func (s *PipelineTestSuite) TestSyntheticSource() {
+	source, err := NewSyntheticSource(SyntheticConfig{Width: 160, Height: 80, FPS: 10, Frames: 3})
+	s.Require().NoError(err)
+	source.toMat = func(image.Image) (gocv.Mat, error) {
+		return gocv.Mat{}, nil
+	}
+
+	frames, err := readAll(source)
+	s.ErrorIs(err, io.EOF)
+	s.Require().Len(frames, 3)
+	s.Equal(epoch.Add(200*time.Millisecond), frames[2].Timestamp)
+
+	// The square moves diagonally and bounces off the edges
+	s.Equal(image.Rect(0, 0, 10, 10), source.Square(0))
+	s.Equal(image.Rect(40, 30, 50, 40), source.Square(10))
+	s.Equal(image.Rect(140, 20, 150, 30), source.Square(40))
+
+	pattern := source.Pattern(0)
+	s.Equal(image.Rect(0, 0, 160, 80), pattern.Bounds())
+	s.Equal(color.RGBA{255, 255, 255, 255}, pattern.RGBAAt(5, 5))
+	s.Equal(bars[len(bars)-1], pattern.RGBAAt(159, 79))
+
+	_, err = NewSyntheticSource(SyntheticConfig{Width: -1})
+	s.Error(err)
+}
+
+func (s *PipelineTestSuite) TestSyntheticSourceRealtime() {
+	source, err := NewSyntheticSource(SyntheticConfig{FPS: 10, Realtime: true})
+	s.Require().NoError(err)
+	source.toMat = func(image.Image) (gocv.Mat, error) {
+		return gocv.Mat{}, nil
+	}
+	waits := []time.Duration{}
+	source.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := source.Read(context.Background())
+		s.Require().NoError(err)
+	}
+	s.Require().Len(waits, 3)
+	s.InDelta(200*time.Millisecond, waits[2], float64(50*time.Millisecond))
+}
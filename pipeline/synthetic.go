@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// Default constants for initialising a synthetic source.
+const (
+	DefaultSyntheticWidth  = 640
+	DefaultSyntheticHeight = 480
+)
+
+// bars the colours of the test pattern background.
+var bars = []color.RGBA{
+	{192, 192, 192, 255},
+	{192, 192, 0, 255},
+	{0, 192, 192, 255},
+	{0, 192, 0, 255},
+	{192, 0, 192, 255},
+	{192, 0, 0, 255},
+	{0, 0, 192, 255},
+}
+
+// SyntheticConfig can be used to configure a synthetic source.
+type SyntheticConfig struct {
+	Width  int
+	Height int
+	FPS    float64
+	// Frames is the amount of frames after which the source ends, zero never ends
+	Frames int
+	// Realtime paces the frames at the frame rate, otherwise frames are generated as fast as possible
+	Realtime bool
+}
+
+// SyntheticSource generates a test pattern of colour bars with a white square moving across
+// it, which is useful to test a pipeline without a camera or video.
+type SyntheticSource struct {
+	config SyntheticConfig
+	index  int
+	start  time.Time
+	toMat  func(image.Image) (gocv.Mat, error)
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewSyntheticSource creates a synthetic source, zero values in the config are replaced with their defaults.
+func NewSyntheticSource(config SyntheticConfig) (*SyntheticSource, error) {
+	if config.Width == 0 {
+		config.Width = DefaultSyntheticWidth
+	}
+	if config.Height == 0 {
+		config.Height = DefaultSyntheticHeight
+	}
+	if config.FPS == 0 {
+		config.FPS = DefaultFPS
+	}
+	if config.Width < 0 || config.Height < 0 || config.FPS < 0 || config.Frames < 0 {
+		return nil, fmt.Errorf("synthetic source config should not contain negative values")
+	}
+	return &SyntheticSource{
+		config: config,
+		toMat:  gocv.ImageToMatRGB,
+		sleep:  sleep,
+	}, nil
+}
+
+// Read generates the next frame, io.EOF is returned once the configured amount of frames is reached.
+func (s *SyntheticSource) Read(ctx context.Context) (Frame, error) {
+	if err := ctx.Err(); err != nil {
+		return Frame{}, err
+	}
+	if s.config.Frames > 0 && s.index >= s.config.Frames {
+		return Frame{}, io.EOF
+	}
+
+	timestamp := position(s.index, s.config.FPS)
+	if s.config.Realtime {
+		if s.index == 0 {
+			s.start = time.Now()
+		}
+		if err := s.sleep(ctx, time.Until(s.start.Add(timestamp.Sub(epoch)))); err != nil {
+			return Frame{}, err
+		}
+		timestamp = time.Now()
+	}
+
+	mat, err := s.toMat(s.Pattern(s.index))
+	if err != nil {
+		return Frame{}, fmt.Errorf("unable to convert test pattern: %w", err)
+	}
+	frame := Frame{Index: s.index, Timestamp: timestamp, Mat: mat}
+	s.index++
+	return frame, nil
+}
+
+// Pattern returns the test pattern of the frame with given index.
+func (s *SyntheticSource) Pattern(index int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, s.config.Width, s.config.Height))
+	for i, bar := range bars {
+		r := image.Rect(i*s.config.Width/len(bars), 0, (i+1)*s.config.Width/len(bars), s.config.Height)
+		draw.Draw(img, r, &image.Uniform{C: bar}, image.Point{}, draw.Src)
+	}
+	draw.Draw(img, s.Square(index), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	return img
+}
+
+// Square returns the position of the moving square in the frame with given index, it moves
+// diagonally and bounces off the edges of the frame.
+func (s *SyntheticSource) Square(index int) image.Rectangle {
+	size := min(s.config.Width, s.config.Height) / 8
+	return image.Rect(0, 0, size, size).Add(image.Pt(
+		bounce(4*index, s.config.Width-size),
+		bounce(3*index, s.config.Height-size),
+	))
+}
+
+// bounce returns the position of an object which moved given distance back and forth between 0 and limit.
+func bounce(distance, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	pos := distance % (2 * limit)
+	if pos > limit {
+		return 2*limit - pos
+	}
+	return pos
+}
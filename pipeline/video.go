@@ -0,0 +1,298 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// Default constants for initialising sources.
+const (
+	DefaultFPS            = 25.0
+	DefaultBackoff        = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultMaxEmptyFrames = 30
+)
+
+// errEmptyFrame is returned by a capture which read an empty frame, webcams return these while
+// warming up.
+var errEmptyFrame = errors.New("empty frame")
+
+// epoch is the timestamp of the first frame of sources which are not live, such as video files,
+// so the timestamps of their frames represent the position in the source.
+var epoch = time.Unix(0, 0).UTC()
+
+// capture reads frames from a video file, stream or device.
+type capture interface {
+	// read returns the next frame, io.EOF is returned when no frame could be read and
+	// errEmptyFrame when the frame is empty
+	read() (gocv.Mat, error)
+	get(gocv.VideoCaptureProperties) float64
+	Close() error
+}
+
+// gocvCapture a capture backed by a gocv video capture.
+type gocvCapture struct {
+	vc *gocv.VideoCapture
+}
+
+func openCapture(device interface{}) (capture, error) {
+	vc, err := gocv.OpenVideoCapture(device)
+	return newGocvCapture(vc, err, device)
+}
+
+// newGocvCapture wraps an opened video capture, it fails when the capture could not be opened.
+func newGocvCapture(vc *gocv.VideoCapture, err error, device interface{}) (capture, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !vc.IsOpened() {
+		// nolint: errcheck
+		vc.Close()
+		return nil, fmt.Errorf("unable to open video capture %v", device)
+	}
+	return &gocvCapture{vc: vc}, nil
+}
+
+func (c *gocvCapture) read() (gocv.Mat, error) {
+	mat := gocv.NewMat()
+	ok := c.vc.Read(&mat)
+	if ok && !mat.Empty() {
+		return mat, nil
+	}
+	// nolint: errcheck
+	mat.Close()
+	if !ok {
+		return gocv.Mat{}, io.EOF
+	}
+	return gocv.Mat{}, errEmptyFrame
+}
+
+func (c *gocvCapture) get(prop gocv.VideoCaptureProperties) float64 {
+	return c.vc.Get(prop)
+}
+
+func (c *gocvCapture) Close() error {
+	return c.vc.Close()
+}
+
+// VideoConfig can be used to configure a video source.
+type VideoConfig struct {
+	// Live sources, such as capture devices and network streams, timestamp frames with the moment
+	// they were read and reconnect when reading fails. Other sources end at the first failed read
+	// and timestamp frames with their position in the video, relative to the Unix epoch.
+	Live bool
+	// MaxReconnects is the amount of consecutive reconnect attempts before reading fails, a
+	// negative value retries forever
+	MaxReconnects int
+	// Backoff is the wait before the first reconnect attempt, it doubles for every following
+	// attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxEmptyFrames is the amount of consecutive empty frames which are skipped before reading is
+	// considered failed, a negative value skips empty frames forever. Zero defaults to
+	// DefaultMaxEmptyFrames
+	MaxEmptyFrames int
+}
+
+// VideoSource reads frames from a video file, a network stream or a capture device.
+type VideoSource struct {
+	mu      sync.Mutex
+	device  interface{}
+	config  VideoConfig
+	open    func(device interface{}) (capture, error)
+	sleep   func(ctx context.Context, d time.Duration) error
+	capture capture
+	fps     float64
+	size    image.Point
+	frames  int
+	index   int
+	closed  bool
+}
+
+// NewVideoFileSource creates a source which reads the frames of given video file.
+func NewVideoFileSource(path string) (*VideoSource, error) {
+	return newVideoSource(path, VideoConfig{}, func(interface{}) (capture, error) {
+		vc, err := gocv.VideoCaptureFile(path)
+		return newGocvCapture(vc, err, path)
+	})
+}
+
+// NewCaptureDeviceSource creates a source which reads from the capture device with given ID,
+// it reconnects to the device forever.
+func NewCaptureDeviceSource(id int) (*VideoSource, error) {
+	return NewVideoSource(id, VideoConfig{Live: true, MaxReconnects: -1})
+}
+
+// NewVideoSource creates a source for a device, which is anything accepted by
+// gocv.OpenVideoCapture: a file name, stream URL, GStreamer pipeline or device ID.
+func NewVideoSource(device interface{}, config VideoConfig) (*VideoSource, error) {
+	return newVideoSource(device, config, openCapture)
+}
+
+func newVideoSource(device interface{}, config VideoConfig, open func(interface{}) (capture, error)) (*VideoSource, error) {
+	if config.Backoff == 0 {
+		config.Backoff = DefaultBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.MaxEmptyFrames == 0 {
+		config.MaxEmptyFrames = DefaultMaxEmptyFrames
+	}
+	if config.Backoff < 0 || config.MaxBackoff < config.Backoff {
+		return nil, fmt.Errorf("backoff should be positive and not exceed max backoff, got %v and %v", config.Backoff, config.MaxBackoff)
+	}
+
+	c, err := open(device)
+	if err != nil {
+		return nil, err
+	}
+	s := &VideoSource{
+		device: device,
+		config: config,
+		open:   open,
+		sleep:  sleep,
+	}
+	s.setCapture(c)
+	return s, nil
+}
+
+// setCapture starts reading from given capture and reads its properties.
+func (s *VideoSource) setCapture(c capture) {
+	s.capture = c
+	s.fps = c.get(gocv.VideoCaptureFPS)
+	if s.fps <= 0 {
+		s.fps = DefaultFPS
+	}
+	s.size = image.Pt(int(c.get(gocv.VideoCaptureFrameWidth)), int(c.get(gocv.VideoCaptureFrameHeight)))
+	s.frames = max(int(c.get(gocv.VideoCaptureFrameCount)), 0)
+}
+
+// FPS returns the frame rate reported by the video, or DefaultFPS when it is unknown.
+func (s *VideoSource) FPS() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fps
+}
+
+// FrameSize returns the width and height of the frames as reported by the video.
+func (s *VideoSource) FrameSize() image.Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// FrameCount returns the amount of frames in a video file as reported by the video, which is
+// an estimate for some formats. Zero is returned when it is unknown, such as for live sources.
+func (s *VideoSource) FrameCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frames
+}
+
+// Read returns the next frame, empty frames are skipped. For sources which are not live io.EOF
+// is returned at the end of the video, live sources reconnect with backoff and fail once
+// MaxReconnects is exceeded. io.EOF is returned once the source is closed.
+func (s *VideoSource) Read(ctx context.Context) (Frame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backoff := s.config.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return Frame{}, err
+		}
+		if s.closed {
+			return Frame{}, io.EOF
+		}
+		if s.capture != nil {
+			if mat, err := s.read(); err == nil {
+				return s.frame(mat), nil
+			}
+		}
+		if !s.config.Live {
+			return Frame{}, io.EOF
+		}
+		if s.config.MaxReconnects >= 0 && attempt >= s.config.MaxReconnects {
+			return Frame{}, fmt.Errorf("unable to read from %v after %d reconnect attempts", s.device, attempt)
+		}
+
+		if s.capture != nil {
+			// nolint: errcheck
+			s.capture.Close()
+			s.capture = nil
+		}
+		// The source is unlocked while waiting, so it can be closed and queried in the meantime
+		s.mu.Unlock()
+		err := s.sleep(ctx, backoff)
+		s.mu.Lock()
+		if err != nil {
+			return Frame{}, err
+		}
+		if s.closed {
+			return Frame{}, io.EOF
+		}
+		backoff = min(2*backoff, s.config.MaxBackoff)
+		if c, err := s.open(s.device); err == nil {
+			s.setCapture(c)
+		}
+	}
+}
+
+// read reads the next frame from the capture, skipping up to MaxEmptyFrames consecutive empty frames.
+func (s *VideoSource) read() (gocv.Mat, error) {
+	for empty := 0; ; empty++ {
+		mat, err := s.capture.read()
+		if !errors.Is(err, errEmptyFrame) || s.config.MaxEmptyFrames >= 0 && empty >= s.config.MaxEmptyFrames {
+			return mat, err
+		}
+	}
+}
+
+// frame wraps a read Mat into a frame.
+func (s *VideoSource) frame(mat gocv.Mat) Frame {
+	timestamp := time.Now()
+	if !s.config.Live {
+		timestamp = position(s.index, s.fps)
+	}
+	frame := Frame{Index: s.index, Timestamp: timestamp, Mat: mat}
+	s.index++
+	return frame
+}
+
+// Close closes the underlying capture.
+func (s *VideoSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.capture == nil {
+		return nil
+	}
+	err := s.capture.Close()
+	s.capture = nil
+	return err
+}
+
+// position returns the timestamp of the frame with given index in a video with given frame rate.
+func position(index int, fps float64) time.Time {
+	return epoch.Add(time.Duration(float64(index) / fps * float64(time.Second)))
+}
+
+// sleep waits for given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
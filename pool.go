@@ -0,0 +1,116 @@
+package yolov5
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// NetPool is a fixed size pool of nets which can be used to run detections concurrently.
+// The underlying neural nets are not safe for concurrent use, therefore every detection
+// borrows a net from the pool for the duration of the call.
+type NetPool struct {
+	nets      chan Net
+	members   []Net
+	size      int
+	closeOnce sync.Once
+}
+
+// NewNetPool creates a pool of given size, newNet is used to create every net in the pool.
+func NewNetPool(size int, newNet func() (Net, error)) (*NetPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("net pool size should be at least 1, got %d", size)
+	}
+	pool := &NetPool{
+		nets: make(chan Net, size),
+		size: size,
+	}
+	for i := 0; i < size; i++ {
+		net, err := newNet()
+		if err != nil {
+			for len(pool.nets) > 0 {
+				// nolint: errcheck
+				(<-pool.nets).Close()
+			}
+			return nil, err
+		}
+		pool.nets <- net
+		pool.members = append(pool.members, net)
+	}
+	return pool, nil
+}
+
+// Close waits until all nets are returned to the pool and closes them.
+func (p *NetPool) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		for i := 0; i < p.size; i++ {
+			net := <-p.nets
+			if closeErr := net.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+		close(p.nets)
+	})
+	return err
+}
+
+// GetDetections retrieve predicted detections from given matrix using one of the pooled nets.
+func (p *NetPool) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return p.Detect(frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects, but filter out a given list of coco name ids.
+func (p *NetPool) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
+	return p.Detect(frame, WithClassFilter(classIDsFilter))
+}
+
+// Detect retrieves predicted detections from given matrix using one of the pooled nets.
+func (p *NetPool) Detect(frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	return p.DetectContext(context.Background(), frame, opts...)
+}
+
+// DetectContext waits for a net to become available and uses it to retrieve the detections.
+// Waiting for a net respects the cancellation and deadline of the given context.
+func (p *NetPool) DetectContext(ctx context.Context, frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	net, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(net)
+	return net.DetectContext(ctx, frame, opts...)
+}
+
+// DetectResult waits for a net to become available and uses it to retrieve the detection result.
+// Waiting for a net respects the cancellation and deadline of the given context.
+func (p *NetPool) DetectResult(ctx context.Context, frame gocv.Mat, opts ...DetectOption) (*DetectionResult, error) {
+	net, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(net)
+	return net.DetectResult(ctx, frame, opts...)
+}
+
+// acquire borrows a net from the pool, it blocks until a net is available or the context is done.
+func (p *NetPool) acquire(ctx context.Context) (Net, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("unable to acquire pooled net: %w", err)
+	}
+	select {
+	case net, ok := <-p.nets:
+		if !ok {
+			return nil, fmt.Errorf("net pool is closed")
+		}
+		return net, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("unable to acquire pooled net: %w", ctx.Err())
+	}
+}
+
+// release returns a borrowed net to the pool.
+func (p *NetPool) release(net Net) {
+	p.nets <- net
+}
@@ -0,0 +1,52 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+)
+
+type NetPoolTestSuite struct {
+	suite.Suite
+}
+
+func TestNetPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(NetPoolTestSuite))
+}
+
+func (s *NetPoolTestSuite) TestCorrectImplementation() {
+	var _ Net = &NetPool{}
+}
+
+func (s *NetPoolTestSuite) TestInvalidSize() {
+	_, err := NewNetPool(0, func() (Net, error) { return &yoloNet{}, nil })
+	s.Error(err)
+}
+
+func (s *NetPoolTestSuite) TestUnableToCreateNet() {
+	_, err := NewNetPool(2, func() (Net, error) { return nil, fmt.Errorf("very broken") })
+	s.Equal(fmt.Errorf("very broken"), err)
+}
+
+func (s *NetPoolTestSuite) TestDeadlineWhileWaitingForNet() {
+	pool, err := NewNetPool(1, func() (Net, error) { return &yoloNet{}, nil })
+	s.Require().NoError(err)
+
+	net, err := pool.acquire(context.Background())
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.DetectContext(ctx, gocv.Mat{})
+	s.True(errors.Is(err, context.DeadlineExceeded))
+
+	pool.release(net)
+	acquired, err := pool.acquire(context.Background())
+	s.Require().NoError(err)
+	s.Equal(net, acquired)
+}
@@ -0,0 +1,75 @@
+package yolov5
+
+import (
+	"context"
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+// Reload replaces the model of the net with the model and coco names of given paths. The new
+// model is created with the NewNet function of the config and validated with a warm-up
+// inference before it is swapped in. Detections which are in flight finish on the old model,
+// which is closed as soon as they are done.
+func (y *yoloNet) Reload(modelPath, cocoNamePath string) error {
+	m, err := loadModel(modelPath, cocoNamePath, y.config)
+	if err != nil {
+		return err
+	}
+
+	err = y.warmUp(m)
+	if err != nil {
+		// nolint: errcheck
+		m.net.Close()
+		return fmt.Errorf("unable to warm up reloaded model: %w", err)
+	}
+
+	return y.swapModel(m)
+}
+
+// warmUp runs an inference on a blank frame to validate the given model.
+func (y *yoloNet) warmUp(m *model) (err error) {
+	defer recoverPanic("warm-up", &err)
+	frame := gocv.NewMatWithSize(y.DefaultInputHeight, y.DefaultInputWidth, gocv.MatTypeCV8UC3)
+	// nolint: errcheck
+	defer frame.Close()
+
+	_, err = y.detect(context.Background(), m, frame, y.detectOptions(nil))
+	return err
+}
+
+// swapModel atomically replaces the current model, waits for the detections which are
+// still using the old model and closes it. When the net was closed during the reload, the
+// new model is closed instead.
+func (y *yoloNet) swapModel(m *model) error {
+	y.mu.Lock()
+	if y.closed {
+		y.mu.Unlock()
+		// nolint: errcheck
+		m.net.Close()
+		return ErrClosed
+	}
+	old := y.model
+	y.model = m
+	y.mu.Unlock()
+
+	old.inFlight.Wait()
+	return old.net.Close()
+}
+
+// Reload reloads the model of every net in the pool. The nets are reloaded one by one,
+// so the pool keeps serving detections during the reload. If a net fails to reload, the
+// nets which were already reloaded keep using the new model.
+func (p *NetPool) Reload(modelPath, cocoNamePath string) error {
+	for i, net := range p.members {
+		reloader, ok := net.(Reloader)
+		if !ok {
+			return fmt.Errorf("net %d of the pool does not support reloading", i)
+		}
+		err := reloader.Reload(modelPath, cocoNamePath)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
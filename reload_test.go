@@ -0,0 +1,104 @@
+package yolov5
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/internal/ml/mocks"
+)
+
+type ReloadTestSuite struct {
+	suite.Suite
+}
+
+func TestReloadTestSuite(t *testing.T) {
+	suite.Run(t, new(ReloadTestSuite))
+}
+
+func (s *ReloadTestSuite) TestCorrectImplementation() {
+	var _ Reloader = &yoloNet{}
+	var _ Reloader = &NetPool{}
+}
+
+func (s *ReloadTestSuite) TestReloadNonExistentModel() {
+	controller := gomock.NewController(s.T())
+	old := &model{net: mocks.NewMockNeuralNet(controller)}
+	y := &yoloNet{model: old}
+
+	err := y.Reload("data/yolov5/notexistent", "data/yolov5/coco.names")
+	s.Error(err)
+	s.Equal(old, y.model)
+}
+
+func (s *ReloadTestSuite) TestSwapModelDrainsInFlight() {
+	controller := gomock.NewController(s.T())
+	oldNet := mocks.NewMockNeuralNet(controller)
+	old := &model{net: oldNet, modelID: "old"}
+	y := &yoloNet{model: old}
+
+	inFlight := y.acquireModel()
+	s.Equal(old, inFlight)
+
+	closed := make(chan struct{})
+	oldNet.EXPECT().Close().DoAndReturn(func() error {
+		close(closed)
+		return nil
+	}).Times(1)
+
+	swapped := make(chan error)
+	go func() {
+		swapped <- y.swapModel(&model{net: mocks.NewMockNeuralNet(controller), modelID: "new"})
+	}()
+
+	s.Eventually(func() bool {
+		m := y.acquireModel()
+		defer m.inFlight.Done()
+		return m.modelID == "new"
+	}, time.Second, time.Millisecond)
+
+	select {
+	case <-closed:
+		s.Fail("old model closed while a detection was in flight")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	inFlight.inFlight.Done()
+	s.NoError(<-swapped)
+	<-closed
+}
+
+func (s *ReloadTestSuite) TestReloadWhileClosing() {
+	for i := 0; i < 100; i++ {
+		controller := gomock.NewController(s.T())
+		oldNet, newNet := mocks.NewMockNeuralNet(controller), mocks.NewMockNeuralNet(controller)
+		y := &yoloNet{model: &model{net: oldNet}}
+
+		// Whichever finishes first, both models are closed exactly once
+		oldNet.EXPECT().Close().Return(nil).Times(1)
+		newNet.EXPECT().Close().Return(nil).Times(1)
+
+		var swapErr, closeErr error
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			swapErr = y.swapModel(&model{net: newNet})
+		}()
+		go func() {
+			defer wg.Done()
+			closeErr = y.Close()
+		}()
+		wg.Wait()
+
+		s.NoError(closeErr)
+		if swapErr != nil {
+			s.ErrorIs(swapErr, ErrClosed)
+		}
+		s.NoError(y.Close())
+		controller.Finish()
+	}
+}
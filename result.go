@@ -0,0 +1,38 @@
+package yolov5
+
+import (
+	"image"
+	"time"
+)
+
+// DetectionResult contains the detections of a single frame, together with information
+// on how the frame was processed and how long every stage of the detection took.
+type DetectionResult struct {
+	Detections []ObjectDetection
+	// FrameSize is the size of the frame the detection was run on
+	FrameSize image.Point
+	// Transform describes how the frame was mapped onto the input of the network
+	Transform Transform
+	// Model identifies the model which produced the detections
+	Model string
+
+	// Durations of the preprocess, forward and postprocess stages
+	Preprocess  time.Duration
+	Forward     time.Duration
+	Postprocess time.Duration
+}
+
+// Transform describes how the (region of the) frame was scaled to fit the input of the
+// network. A bounding box in network input coordinates is mapped back onto the frame by
+// multiplying by the scale and adding the offset.
+type Transform struct {
+	ScaleX float32
+	ScaleY float32
+	// Offset of the region of interest within the frame
+	Offset image.Point
+}
+
+// Total returns the total duration of the detection.
+func (r *DetectionResult) Total() time.Duration {
+	return r.Preprocess + r.Forward + r.Postprocess
+}
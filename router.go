@@ -0,0 +1,170 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"gocv.io/x/gocv"
+)
+
+// Default constants for initialising the router.
+const (
+	DefaultUncertainLow  float32 = 0.25
+	DefaultUncertainHigh float32 = 0.6
+)
+
+// RouterConfig can be used to configure a router.
+type RouterConfig struct {
+	// Detections of the small model with a confidence in [UncertainLow, UncertainHigh) are considered
+	// uncertain, a frame is escalated when it contains an uncertain detection or no detection of at
+	// least UncertainHigh
+	UncertainLow  float32
+	UncertainHigh float32
+}
+
+// RouterStats contains the amount of frames processed by a router and how many of them were escalated.
+type RouterStats struct {
+	Frames      uint64
+	Escalations uint64
+}
+
+// EscalationRate returns the fraction of frames which were escalated to the large model.
+func (s RouterStats) EscalationRate() float64 {
+	if s.Frames == 0 {
+		return 0
+	}
+	return float64(s.Escalations) / float64(s.Frames)
+}
+
+// Router runs a small model first and only escalates the frame to a large model when the
+// small model is uncertain. The model which produced the detections is reported by the
+// model of the detection result.
+type Router struct {
+	small  Net
+	large  Net
+	config RouterConfig
+
+	frames      atomic.Uint64
+	escalations atomic.Uint64
+}
+
+// NewRouter creates a router which escalates the uncertain frames of the small net to the large net.
+func NewRouter(small, large Net, config RouterConfig) (*Router, error) {
+	if small == nil || large == nil {
+		return nil, fmt.Errorf("router requires both a small and a large net")
+	}
+	if config.UncertainLow == 0 && config.UncertainHigh == 0 {
+		config.UncertainLow = DefaultUncertainLow
+		config.UncertainHigh = DefaultUncertainHigh
+	}
+	if config.UncertainLow < 0 || config.UncertainHigh > 1 || config.UncertainLow >= config.UncertainHigh {
+		return nil, fmt.Errorf("router uncertainty band [%f, %f) is invalid", config.UncertainLow, config.UncertainHigh)
+	}
+	return &Router{
+		small:  small,
+		large:  large,
+		config: config,
+	}, nil
+}
+
+// Stats returns the amount of processed and escalated frames.
+func (r *Router) Stats() RouterStats {
+	return RouterStats{
+		Frames:      r.frames.Load(),
+		Escalations: r.escalations.Load(),
+	}
+}
+
+// Close closes both nets.
+func (r *Router) Close() error {
+	return errors.Join(r.small.Close(), r.large.Close())
+}
+
+// GetDetections retrieve predicted detections from given matrix.
+func (r *Router) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
+	return r.Detect(frame)
+}
+
+// GetDetectionsWithFilter allows you to detect objects, but filter out a given list of coco name ids.
+func (r *Router) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
+	return r.Detect(frame, WithClassFilter(classIDsFilter))
+}
+
+// Detect retrieves predicted detections from given matrix.
+func (r *Router) Detect(frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	return r.DetectContext(context.Background(), frame, opts...)
+}
+
+// DetectContext retrieves predicted detections from given matrix.
+func (r *Router) DetectContext(ctx context.Context, frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	result, err := r.DetectResult(ctx, frame, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return result.Detections, nil
+}
+
+// DetectResult runs the small net on given frame and escalates to the large net when needed.
+// The small net detects down to the lower bound of the uncertainty band, the confidence threshold
+// and maximum amount of detections of the options are applied to its detections afterwards.
+// The durations of an escalated result include the durations of both nets.
+func (r *Router) DetectResult(ctx context.Context, frame gocv.Mat, opts ...DetectOption) (*DetectionResult, error) {
+	o := DetectOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	smallOpts := append(append([]DetectOption{}, opts...), WithConfidenceThreshold(r.config.UncertainLow), WithMaxDetections(0))
+
+	r.frames.Add(1)
+	small, err := r.small.DetectResult(ctx, frame, smallOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if !r.shouldEscalate(small.Detections) {
+		small.Detections = limitDetections(small.Detections, o.ConfidenceThreshold, o.MaxDetections)
+		return small, nil
+	}
+
+	r.escalations.Add(1)
+	large, err := r.large.DetectResult(ctx, frame, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("escalated detection failed: %w", err)
+	}
+	large.Preprocess += small.Preprocess
+	large.Forward += small.Forward
+	large.Postprocess += small.Postprocess
+	return large, nil
+}
+
+// shouldEscalate reports whether the detections contain an uncertain detection or no confident detection.
+func (r *Router) shouldEscalate(detections []ObjectDetection) bool {
+	confident := false
+	for _, detection := range detections {
+		if detection.Confidence >= r.config.UncertainHigh {
+			confident = true
+			continue
+		}
+		if detection.Confidence >= r.config.UncertainLow {
+			return true
+		}
+	}
+	return !confident
+}
+
+// limitDetections filters the detections on confidence and limits their amount, zero means no limit.
+// The detections are expected to be sorted on confidence, highest first.
+func limitDetections(detections []ObjectDetection, confidenceThreshold float32, max int) []ObjectDetection {
+	limited := []ObjectDetection{}
+	for _, detection := range detections {
+		if max > 0 && len(limited) == max {
+			break
+		}
+		if detection.Confidence < confidenceThreshold {
+			continue
+		}
+		limited = append(limited, detection)
+	}
+	return limited
+}
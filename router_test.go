@@ -0,0 +1,142 @@
+package yolov5
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gocv.io/x/gocv"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+}
+
+func TestRouterTestSuite(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
+
+func (s *RouterTestSuite) TestCorrectImplementation() {
+	var _ Net = &Router{}
+}
+
+func (s *RouterTestSuite) TestNewRouterInvalidConfig() {
+	_, err := NewRouter(nil, &staticNet{}, RouterConfig{})
+	s.Error(err)
+	_, err = NewRouter(&staticNet{}, &staticNet{}, RouterConfig{UncertainLow: 0.6, UncertainHigh: 0.3})
+	s.Error(err)
+	_, err = NewRouter(&staticNet{}, &staticNet{}, RouterConfig{UncertainLow: 0.5, UncertainHigh: 1.5})
+	s.Error(err)
+
+	router, err := NewRouter(&staticNet{}, &staticNet{}, RouterConfig{})
+	s.Require().NoError(err)
+	s.Equal(DefaultUncertainLow, router.config.UncertainLow)
+	s.Equal(DefaultUncertainHigh, router.config.UncertainHigh)
+}
+
+func (s *RouterTestSuite) TestDetectResult() {
+	large := &staticNet{result: &DetectionResult{
+		Model:   "yolov5x.onnx",
+		Forward: 50 * time.Millisecond,
+		Detections: []ObjectDetection{
+			{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+		},
+	}}
+
+	tests := []struct {
+		Name          string
+		Detections    []ObjectDetection
+		Options       []DetectOption
+		Escalated     bool
+		ExpectedCount int
+	}{
+		{
+			Name: "Confident detections are not escalated",
+			Detections: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+				{ClassID: 2, ClassName: "car", Confidence: 0.7, BoundingBox: image.Rect(100, 100, 200, 200)},
+			},
+			ExpectedCount: 2,
+		},
+		{
+			Name: "Options are applied to detections of the small model",
+			Detections: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+				{ClassID: 2, ClassName: "car", Confidence: 0.7, BoundingBox: image.Rect(100, 100, 200, 200)},
+			},
+			Options:       []DetectOption{WithMaxDetections(1)},
+			ExpectedCount: 1,
+		},
+		{
+			Name: "Uncertain detection is escalated",
+			Detections: []ObjectDetection{
+				{ClassID: 0, ClassName: "person", Confidence: 0.9, BoundingBox: image.Rect(0, 0, 20, 20)},
+				{ClassID: 2, ClassName: "car", Confidence: 0.4, BoundingBox: image.Rect(100, 100, 200, 200)},
+			},
+			Escalated:     true,
+			ExpectedCount: 1,
+		},
+		{
+			Name:          "Frame without confident detections is escalated",
+			Detections:    []ObjectDetection{},
+			Escalated:     true,
+			ExpectedCount: 1,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			small := &staticNet{result: &DetectionResult{
+				Model:      "yolov5n.onnx",
+				Forward:    10 * time.Millisecond,
+				Detections: test.Detections,
+			}}
+			router, err := NewRouter(small, large, RouterConfig{})
+			s.Require().NoError(err)
+
+			result, err := router.DetectResult(context.Background(), gocv.Mat{}, test.Options...)
+			s.Require().NoError(err)
+			s.Len(result.Detections, test.ExpectedCount)
+			s.Equal(DefaultUncertainLow, small.options.ConfidenceThreshold)
+			s.Equal(0, small.options.MaxDetections)
+
+			stats := router.Stats()
+			s.Equal(uint64(1), stats.Frames)
+			if test.Escalated {
+				s.Equal("yolov5x.onnx", result.Model)
+				s.Equal(uint64(1), stats.Escalations)
+				return
+			}
+			s.Equal("yolov5n.onnx", result.Model)
+			s.Equal(uint64(0), stats.Escalations)
+		})
+	}
+}
+
+func (s *RouterTestSuite) TestEscalatedDurations() {
+	small := &staticNet{result: &DetectionResult{Forward: 10 * time.Millisecond}}
+	large := &staticNet{result: &DetectionResult{Forward: 50 * time.Millisecond}}
+	router, err := NewRouter(small, large, RouterConfig{})
+	s.Require().NoError(err)
+
+	result, err := router.DetectResult(context.Background(), gocv.Mat{})
+	s.Require().NoError(err)
+	s.Equal(60*time.Millisecond, result.Forward)
+}
+
+func (s *RouterTestSuite) TestEscalationRate() {
+	s.Equal(float64(0), RouterStats{}.EscalationRate())
+	s.Equal(0.25, RouterStats{Frames: 8, Escalations: 2}.EscalationRate())
+}
+
+func (s *RouterTestSuite) TestDetectLargeError() {
+	largeErr := errors.New("forward failed")
+	router, err := NewRouter(&staticNet{result: &DetectionResult{}}, &staticNet{err: largeErr}, RouterConfig{})
+	s.Require().NoError(err)
+
+	_, err = router.Detect(gocv.Mat{})
+	s.ErrorIs(err, largeErr)
+}
@@ -0,0 +1,221 @@
+// Package smooth suppresses flickering detections in a video without the cost of full tracking.
+//
+// An object is shown once it was detected in N out of the last M frames, it is kept for K frames
+// after it was last detected and its bounding box is smoothed with an exponential moving average.
+// Detections are matched with the objects of the previous frames greedily on intersection over union.
+package smooth
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/wimspaargaren/yolov5/detect"
+	"github.com/wimspaargaren/yolov5/internal/postprocess"
+)
+
+// Default constants for initialising the filter.
+const (
+	DefaultMinHits           = 3
+	DefaultWindow            = 5
+	DefaultMaxMissed         = 5
+	DefaultAlpha             = 0.5
+	DefaultMatchIoU  float32 = 0.3
+)
+
+// Config can be used to customise the filter.
+type Config struct {
+	// MinHits is the amount of frames out of the last Window frames in which an object should be
+	// detected before it is shown
+	MinHits int
+	Window  int
+	// MaxMissed is the amount of frames a shown object is kept after it was last detected, a
+	// negative value disables the keep-alive so an object is dropped in the first frame it is missed
+	MaxMissed int
+	// Alpha is the weight of a new bounding box in the moving average, between 0 and 1,
+	// a higher value follows the detections more closely
+	Alpha float64
+	// MatchIoU is the minimum intersection over union between an object and a detection for them to be matched
+	MatchIoU float32
+	// MatchAcrossClasses allows objects to be matched with detections of a different class
+	MatchAcrossClasses bool
+}
+
+// DefaultConfig used to create a filter which works for most videos out of the box.
+func DefaultConfig() Config {
+	return Config{
+		MinHits:   DefaultMinHits,
+		Window:    DefaultWindow,
+		MaxMissed: DefaultMaxMissed,
+		Alpha:     DefaultAlpha,
+		MatchIoU:  DefaultMatchIoU,
+	}
+}
+
+// object an object which is followed by the filter.
+type object struct {
+	detection detect.ObjectDetection
+	// box is the smoothed bounding box as min x, min y, max x & max y
+	box [4]float64
+	// hits contains for the last frames, oldest first, whether the object was detected
+	hits      []bool
+	confirmed bool
+	missed    int
+}
+
+// Filter smooths the detections of consecutive frames.
+// The filter is not safe for concurrent use.
+type Filter struct {
+	config  Config
+	objects []*object
+}
+
+// New creates a new filter, zero values in the config are replaced with their defaults.
+func New(config Config) (*Filter, error) {
+	defaults := DefaultConfig()
+	if config.MinHits == 0 {
+		config.MinHits = defaults.MinHits
+	}
+	if config.Window == 0 {
+		config.Window = defaults.Window
+	}
+	if config.MaxMissed == 0 {
+		config.MaxMissed = defaults.MaxMissed
+	}
+	if config.Alpha == 0 {
+		config.Alpha = defaults.Alpha
+	}
+	if config.MatchIoU == 0 {
+		config.MatchIoU = defaults.MatchIoU
+	}
+
+	if config.MinHits < 1 || config.MinHits > config.Window {
+		return nil, fmt.Errorf("min hits should be between 1 and window %d, got %d", config.Window, config.MinHits)
+	}
+	if config.MaxMissed < 0 {
+		config.MaxMissed = 0
+	}
+	if config.Alpha < 0 || config.Alpha > 1 {
+		return nil, fmt.Errorf("alpha should be between 0 and 1, got %v", config.Alpha)
+	}
+	if config.MatchIoU < 0 || config.MatchIoU > 1 {
+		return nil, fmt.Errorf("match IoU should be between 0 and 1, got %v", config.MatchIoU)
+	}
+	return &Filter{config: config}, nil
+}
+
+// Update processes the detections of the next frame and returns the objects which should be
+// shown, with smoothed bounding boxes. Objects which were missed keep their last detection.
+func (f *Filter) Update(detections []detect.ObjectDetection) []detect.ObjectDetection {
+	matched := make([]bool, len(f.objects))
+	for detectionIndex, objectIndex := range f.match(detections) {
+		detection := detections[detectionIndex]
+		if objectIndex < 0 {
+			f.objects = append(f.objects, &object{detection: detection, box: toBox(detection.BoundingBox)})
+			matched = append(matched, true)
+			continue
+		}
+		o := f.objects[objectIndex]
+		o.detection = detection
+		box := toBox(detection.BoundingBox)
+		for i := range o.box {
+			o.box[i] = f.config.Alpha*box[i] + (1-f.config.Alpha)*o.box[i]
+		}
+		matched[objectIndex] = true
+	}
+
+	kept := f.objects[:0]
+	for i, o := range f.objects {
+		o.hits = append(o.hits, matched[i])
+		if len(o.hits) > f.config.Window {
+			o.hits = o.hits[1:]
+		}
+		if matched[i] {
+			o.missed = 0
+		} else {
+			o.missed++
+		}
+		if !o.confirmed && o.hitCount() >= f.config.MinHits {
+			o.confirmed = true
+		}
+
+		if o.confirmed && o.missed > f.config.MaxMissed || !o.confirmed && o.hitCount() == 0 {
+			continue
+		}
+		kept = append(kept, o)
+	}
+	f.objects = kept
+
+	res := []detect.ObjectDetection{}
+	for _, o := range f.objects {
+		if !o.confirmed {
+			continue
+		}
+		detection := o.detection
+		detection.BoundingBox = image.Rect(
+			int(math.Round(o.box[0])), int(math.Round(o.box[1])),
+			int(math.Round(o.box[2])), int(math.Round(o.box[3])),
+		)
+		res = append(res, detection)
+	}
+	return res
+}
+
+// Reset forgets all objects, for example when the scene changes.
+func (f *Filter) Reset() {
+	f.objects = nil
+}
+
+// match returns for every detection the index of the object it is matched with, or -1. Pairs
+// are matched greedily, starting with the highest intersection over union.
+func (f *Filter) match(detections []detect.ObjectDetection) []int {
+	type pair struct {
+		detection, object int
+		iou               float32
+	}
+	pairs := []pair{}
+	for i, detection := range detections {
+		for j, o := range f.objects {
+			if !f.config.MatchAcrossClasses && detection.ClassID != o.detection.ClassID {
+				continue
+			}
+			iou := postprocess.IoU(detection.BoundingBox, o.detection.BoundingBox)
+			if iou >= f.config.MatchIoU {
+				pairs = append(pairs, pair{detection: i, object: j, iou: iou})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].iou > pairs[j].iou
+	})
+
+	res := make([]int, len(detections))
+	for i := range res {
+		res[i] = -1
+	}
+	used := make([]bool, len(f.objects))
+	for _, p := range pairs {
+		if res[p.detection] >= 0 || used[p.object] {
+			continue
+		}
+		res[p.detection] = p.object
+		used[p.object] = true
+	}
+	return res
+}
+
+// hitCount returns the amount of frames within the window in which the object was detected.
+func (o *object) hitCount() int {
+	count := 0
+	for _, hit := range o.hits {
+		if hit {
+			count++
+		}
+	}
+	return count
+}
+
+func toBox(r image.Rectangle) [4]float64 {
+	return [4]float64{float64(r.Min.X), float64(r.Min.Y), float64(r.Max.X), float64(r.Max.Y)}
+}
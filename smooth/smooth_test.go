@@ -0,0 +1,109 @@
+package smooth
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/detect"
+)
+
+type SmoothTestSuite struct {
+	suite.Suite
+}
+
+func TestSmoothTestSuite(t *testing.T) {
+	suite.Run(t, new(SmoothTestSuite))
+}
+
+// detection creates a detection of given class with a 40x60 box at given position.
+func detection(classID, x, y int) detect.ObjectDetection {
+	return detect.ObjectDetection{
+		ClassID:     classID,
+		ClassName:   []string{"person", "car"}[classID],
+		Confidence:  0.6,
+		BoundingBox: image.Rect(x, y, x+40, y+60),
+	}
+}
+
+func (s *SmoothTestSuite) TestNewInvalidConfig() {
+	_, err := New(Config{MinHits: 6, Window: 5})
+	s.Error(err)
+	_, err = New(Config{Alpha: 1.5})
+	s.Error(err)
+	_, err = New(Config{MatchIoU: -0.1})
+	s.Error(err)
+}
+
+func (s *SmoothTestSuite) TestConfirmAndKeepAlive() {
+	filter, err := New(Config{MinHits: 2, Window: 3, MaxMissed: 2, Alpha: 1})
+	s.Require().NoError(err)
+
+	present := []detect.ObjectDetection{detection(0, 10, 10)}
+	s.Empty(filter.Update(present))
+	s.Empty(filter.Update(nil))
+	// Detected in 2 out of the last 3 frames
+	s.Equal(present, filter.Update(present))
+
+	// The object is kept for 2 frames after it disappears
+	s.Equal(present, filter.Update(nil))
+	s.Equal(present, filter.Update(nil))
+	s.Empty(filter.Update(nil))
+
+	// Once forgotten, the object has to be confirmed again
+	s.Empty(filter.Update(present))
+}
+
+func (s *SmoothTestSuite) TestNoKeepAlive() {
+	filter, err := New(Config{MinHits: 1, MaxMissed: -1})
+	s.Require().NoError(err)
+
+	present := []detect.ObjectDetection{detection(0, 10, 10)}
+	s.Len(filter.Update(present), 1)
+	s.Empty(filter.Update(nil))
+}
+
+func (s *SmoothTestSuite) TestUnconfirmedObjectsAreForgotten() {
+	filter, err := New(Config{MinHits: 2, Window: 2})
+	s.Require().NoError(err)
+
+	present := []detect.ObjectDetection{detection(0, 10, 10)}
+	s.Empty(filter.Update(present))
+	s.Empty(filter.Update(nil))
+	s.Empty(filter.Update(nil))
+	s.Empty(filter.Update(present))
+	s.Len(filter.Update(present), 1)
+}
+
+func (s *SmoothTestSuite) TestExponentialSmoothing() {
+	filter, err := New(Config{MinHits: 1, Alpha: 0.5})
+	s.Require().NoError(err)
+
+	s.Equal(image.Rect(100, 100, 140, 160), filter.Update([]detect.ObjectDetection{detection(0, 100, 100)})[0].BoundingBox)
+	s.Equal(image.Rect(105, 100, 145, 160), filter.Update([]detect.ObjectDetection{detection(0, 110, 100)})[0].BoundingBox)
+	s.Equal(image.Rect(110, 103, 150, 163), filter.Update([]detect.ObjectDetection{detection(0, 115, 106)})[0].BoundingBox)
+}
+
+func (s *SmoothTestSuite) TestMatching() {
+	filter, err := New(Config{MinHits: 1})
+	s.Require().NoError(err)
+
+	filter.Update([]detect.ObjectDetection{detection(0, 10, 10), detection(0, 200, 10)})
+	res := filter.Update([]detect.ObjectDetection{detection(0, 205, 10), detection(0, 15, 10)})
+	s.Require().Len(res, 2)
+	// Objects keep their order
+	s.Less(res[0].BoundingBox.Min.X, res[1].BoundingBox.Min.X)
+
+	// A detection of another class is a new object
+	filter.Reset()
+	filter.Update([]detect.ObjectDetection{detection(0, 10, 10)})
+	s.Len(filter.Update([]detect.ObjectDetection{detection(1, 10, 10)}), 2)
+
+	filter, err = New(Config{MinHits: 1, MatchAcrossClasses: true})
+	s.Require().NoError(err)
+	filter.Update([]detect.ObjectDetection{detection(0, 10, 10)})
+	res = filter.Update([]detect.ObjectDetection{detection(1, 10, 10)})
+	s.Require().Len(res, 1)
+	s.Equal("car", res[0].ClassName)
+}
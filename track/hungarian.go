@@ -0,0 +1,100 @@
+package track
+
+import "math"
+
+// hungarian solves the assignment problem for given cost matrix, minimising the total cost.
+// The result contains the assigned column of every row, or -1 when the row is not assigned,
+// which happens when there are more rows than columns.
+func hungarian(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return []int{}
+	}
+	cols := len(cost[0])
+	if cols == 0 {
+		return filled(rows, -1)
+	}
+	if rows > cols {
+		transposed := make([][]float64, cols)
+		for j := range transposed {
+			transposed[j] = make([]float64, rows)
+			for i := range cost {
+				transposed[j][i] = cost[i][j]
+			}
+		}
+		assignment := filled(rows, -1)
+		for j, i := range hungarian(transposed) {
+			if i >= 0 {
+				assignment[i] = j
+			}
+		}
+		return assignment
+	}
+
+	// Shortest augmenting path algorithm with potentials, using 1-based indices where
+	// column 0 is a virtual column
+	u := make([]float64, rows+1)
+	v := make([]float64, cols+1)
+	p := make([]int, cols+1)
+	way := make([]int, cols+1)
+	for i := 1; i <= rows; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, cols+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		used := make([]bool, cols+1)
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.Inf(1), 0
+			for j := 1; j <= cols; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= cols; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := filled(rows, -1)
+	for j := 1; j <= cols; j++ {
+		if p[j] != 0 {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+	return assignment
+}
+
+func filled(n, value int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = value
+	}
+	return res
+}
@@ -0,0 +1,132 @@
+package track
+
+import "image"
+
+// Weights of the process and measurement noise, relative to the height of the box.
+const (
+	stdWeightPosition = 1.0 / 20
+	stdWeightVelocity = 1.0 / 160
+)
+
+// kalmanFilter a constant velocity Kalman filter on the box center, aspect ratio and height.
+// The state is [cx, cy, a, h, vcx, vcy, va, vh], the measurement is [cx, cy, a, h].
+type kalmanFilter struct {
+	mean       matrix
+	covariance matrix
+}
+
+var (
+	// transition moves the position by the velocity of a single frame
+	transition = func() matrix {
+		m := identity(8)
+		for i := 0; i < 4; i++ {
+			m.set(i, i+4, 1)
+		}
+		return m
+	}()
+	// observation selects the measured part of the state
+	observation = func() matrix {
+		m := newMatrix(4, 8)
+		for i := 0; i < 4; i++ {
+			m.set(i, i, 1)
+		}
+		return m
+	}()
+)
+
+// newKalmanFilter initialises the filter from a first measurement, with unknown velocity.
+func newKalmanFilter(box image.Rectangle) *kalmanFilter {
+	z := measurement(box)
+	h := z.at(3, 0)
+	return &kalmanFilter{
+		mean: vector(z.at(0, 0), z.at(1, 0), z.at(2, 0), h, 0, 0, 0, 0),
+		covariance: diagonal(
+			square(2*stdWeightPosition*h),
+			square(2*stdWeightPosition*h),
+			square(1e-2),
+			square(2*stdWeightPosition*h),
+			square(10*stdWeightVelocity*h),
+			square(10*stdWeightVelocity*h),
+			square(1e-5),
+			square(10*stdWeightVelocity*h),
+		),
+	}
+}
+
+// predict advances the state by a single frame.
+func (k *kalmanFilter) predict() {
+	h := k.mean.at(3, 0)
+	noise := diagonal(
+		square(stdWeightPosition*h),
+		square(stdWeightPosition*h),
+		square(1e-2),
+		square(stdWeightPosition*h),
+		square(stdWeightVelocity*h),
+		square(stdWeightVelocity*h),
+		square(1e-5),
+		square(stdWeightVelocity*h),
+	)
+	k.mean = transition.mul(k.mean)
+	k.covariance = transition.mul(k.covariance).mul(transition.transpose()).add(noise)
+}
+
+// update corrects the state with given measured box.
+func (k *kalmanFilter) update(box image.Rectangle) {
+	z := measurement(box)
+	h := k.mean.at(3, 0)
+	noise := diagonal(
+		square(stdWeightPosition*h),
+		square(stdWeightPosition*h),
+		square(1e-1),
+		square(stdWeightPosition*h),
+	)
+
+	projected := observation.mul(k.covariance).mul(observation.transpose()).add(noise)
+	inverse, err := projected.inverse()
+	if err != nil {
+		// The covariance collapsed, restart from the measurement
+		*k = *newKalmanFilter(box)
+		return
+	}
+	gain := k.covariance.mul(observation.transpose()).mul(inverse)
+	innovation := z.sub(observation.mul(k.mean))
+	k.mean = k.mean.add(gain.mul(innovation))
+	k.covariance = identity(8).sub(gain.mul(observation)).mul(k.covariance)
+}
+
+// box returns the bounding box of the current state.
+func (k *kalmanFilter) box() image.Rectangle {
+	cx, cy, a, h := k.mean.at(0, 0), k.mean.at(1, 0), k.mean.at(2, 0), k.mean.at(3, 0)
+	w := a * h
+	return image.Rect(round(cx-w/2), round(cy-h/2), round(cx+w/2), round(cy+h/2))
+}
+
+// velocity returns the velocity of the box center in pixels per frame.
+func (k *kalmanFilter) velocity() (float64, float64) {
+	return k.mean.at(4, 0), k.mean.at(5, 0)
+}
+
+// measurement converts a box into the measurement [cx, cy, a, h].
+func measurement(box image.Rectangle) matrix {
+	w, h := float64(box.Dx()), float64(box.Dy())
+	if h < 1 {
+		h = 1
+	}
+	return vector(
+		float64(box.Min.X)+float64(box.Dx())/2,
+		float64(box.Min.Y)+float64(box.Dy())/2,
+		w/h,
+		h,
+	)
+}
+
+func square(v float64) float64 {
+	return v * v
+}
+
+func round(v float64) int {
+	if v < 0 {
+		return int(v - 0.5)
+	}
+	return int(v + 0.5)
+}
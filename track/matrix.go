@@ -0,0 +1,142 @@
+package track
+
+import "fmt"
+
+// matrix a small dense row-major matrix, used by the Kalman filter.
+type matrix struct {
+	rows, cols int
+	data       []float64
+}
+
+func newMatrix(rows, cols int) matrix {
+	return matrix{rows: rows, cols: cols, data: make([]float64, rows*cols)}
+}
+
+// identity creates an identity matrix of size n.
+func identity(n int) matrix {
+	m := newMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.set(i, i, 1)
+	}
+	return m
+}
+
+// diagonal creates a square matrix with given values on its diagonal.
+func diagonal(values ...float64) matrix {
+	m := newMatrix(len(values), len(values))
+	for i, v := range values {
+		m.set(i, i, v)
+	}
+	return m
+}
+
+// vector creates a column vector of given values.
+func vector(values ...float64) matrix {
+	return matrix{rows: len(values), cols: 1, data: values}
+}
+
+func (m matrix) at(i, j int) float64 {
+	return m.data[i*m.cols+j]
+}
+
+func (m matrix) set(i, j int, v float64) {
+	m.data[i*m.cols+j] = v
+}
+
+func (m matrix) mul(o matrix) matrix {
+	res := newMatrix(m.rows, o.cols)
+	for i := 0; i < m.rows; i++ {
+		for k := 0; k < m.cols; k++ {
+			a := m.at(i, k)
+			if a == 0 {
+				continue
+			}
+			for j := 0; j < o.cols; j++ {
+				res.data[i*res.cols+j] += a * o.at(k, j)
+			}
+		}
+	}
+	return res
+}
+
+func (m matrix) add(o matrix) matrix {
+	res := newMatrix(m.rows, m.cols)
+	for i := range m.data {
+		res.data[i] = m.data[i] + o.data[i]
+	}
+	return res
+}
+
+func (m matrix) sub(o matrix) matrix {
+	res := newMatrix(m.rows, m.cols)
+	for i := range m.data {
+		res.data[i] = m.data[i] - o.data[i]
+	}
+	return res
+}
+
+func (m matrix) transpose() matrix {
+	res := newMatrix(m.cols, m.rows)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			res.set(j, i, m.at(i, j))
+		}
+	}
+	return res
+}
+
+// inverse inverts a square matrix using Gauss-Jordan elimination with partial pivoting.
+func (m matrix) inverse() (matrix, error) {
+	n := m.rows
+	a := matrix{rows: n, cols: n, data: append([]float64{}, m.data...)}
+	inv := identity(n)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if abs(a.at(row, col)) > abs(a.at(pivot, col)) {
+				pivot = row
+			}
+		}
+		if abs(a.at(pivot, col)) < 1e-12 {
+			return matrix{}, fmt.Errorf("matrix is singular")
+		}
+		a.swapRows(col, pivot)
+		inv.swapRows(col, pivot)
+
+		p := a.at(col, col)
+		for j := 0; j < n; j++ {
+			a.set(col, j, a.at(col, j)/p)
+			inv.set(col, j, inv.at(col, j)/p)
+		}
+		for row := 0; row < n; row++ {
+			if row == col {
+				continue
+			}
+			f := a.at(row, col)
+			if f == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				a.set(row, j, a.at(row, j)-f*a.at(col, j))
+				inv.set(row, j, inv.at(row, j)-f*inv.at(col, j))
+			}
+		}
+	}
+	return inv, nil
+}
+
+func (m matrix) swapRows(i, j int) {
+	if i == j {
+		return
+	}
+	for k := 0; k < m.cols; k++ {
+		m.data[i*m.cols+k], m.data[j*m.cols+k] = m.data[j*m.cols+k], m.data[i*m.cols+k]
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,304 @@
+// Package track turns the detections of consecutive frames into tracks with a stable identity.
+//
+// The tracker follows ByteTrack (https://arxiv.org/abs/2110.06864): the motion of every track is
+// predicted by a Kalman filter, tracks are matched with high confidence detections first and the
+// tracks which remain unmatched get a second chance with the low confidence detections, which
+// are typically occluded objects. Matching uses the Hungarian algorithm on intersection over union.
+package track
+
+import (
+	"image"
+	"sort"
+
+	"github.com/wimspaargaren/yolov5/detect"
+	"github.com/wimspaargaren/yolov5/internal/postprocess"
+)
+
+// State of a track.
+type State int
+
+// Possible states of a track.
+const (
+	// Tentative tracks are new and not yet matched often enough to be trusted
+	Tentative State = iota
+	// Confirmed tracks have been matched in at least MinHits frames
+	Confirmed
+	// Lost tracks were not matched in the last frame, they are removed after MaxLost frames
+	Lost
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Tentative:
+		return "tentative"
+	case Confirmed:
+		return "confirmed"
+	case Lost:
+		return "lost"
+	default:
+		return "unknown"
+	}
+}
+
+// Default constants for initialising the tracker.
+const (
+	DefaultHighThreshold     float32 = 0.5
+	DefaultLowThreshold      float32 = 0.1
+	DefaultNewTrackThreshold float32 = 0.6
+	DefaultMatchIoU          float32 = 0.2
+	DefaultLowMatchIoU       float32 = 0.5
+	DefaultMinHits                   = 3
+	DefaultMaxLost                   = 30
+)
+
+// Config can be used to customise the tracker.
+type Config struct {
+	// Detections with a confidence of at least HighThreshold are matched first, detections with a
+	// confidence between LowThreshold and HighThreshold are only used to continue existing tracks
+	HighThreshold float32
+	LowThreshold  float32
+	// NewTrackThreshold is the minimum confidence of an unmatched detection to start a new track
+	NewTrackThreshold float32
+	// MatchIoU & LowMatchIoU are the minimum intersection over union between the predicted box of a
+	// track and a high respectively low confidence detection for them to be matched
+	MatchIoU    float32
+	LowMatchIoU float32
+	// MinHits is the amount of matched frames before a track is confirmed
+	MinHits int
+	// MaxLost is the amount of frames a lost track is kept before it is removed
+	MaxLost int
+	// MatchAcrossClasses allows tracks to be matched with detections of a different class
+	MatchAcrossClasses bool
+}
+
+// DefaultConfig used to create a tracker which works for most videos out of the box.
+func DefaultConfig() Config {
+	return Config{
+		HighThreshold:     DefaultHighThreshold,
+		LowThreshold:      DefaultLowThreshold,
+		NewTrackThreshold: DefaultNewTrackThreshold,
+		MatchIoU:          DefaultMatchIoU,
+		LowMatchIoU:       DefaultLowMatchIoU,
+		MinHits:           DefaultMinHits,
+		MaxLost:           DefaultMaxLost,
+	}
+}
+
+// Track is a snapshot of a tracked object.
+type Track struct {
+	ID    int
+	State State
+	// Detection is the detection the track was last matched with
+	Detection detect.ObjectDetection
+	// BoundingBox is the box estimated by the motion model
+	BoundingBox image.Rectangle
+	// VelocityX & VelocityY are the estimated velocity of the box center in pixels per frame
+	VelocityX float64
+	VelocityY float64
+	// Age is the amount of frames since the track was created
+	Age int
+	// Hits is the amount of frames in which the track was matched
+	Hits int
+	// TimeSinceUpdate is the amount of frames since the track was last matched
+	TimeSinceUpdate int
+}
+
+// track the internal state of a track.
+type track struct {
+	Track
+	filter *kalmanFilter
+}
+
+// snapshot returns a copy of the public state of the track.
+func (t *track) snapshot() Track {
+	s := t.Track
+	s.BoundingBox = t.filter.box()
+	s.VelocityX, s.VelocityY = t.filter.velocity()
+	return s
+}
+
+// Tracker assigns detections of consecutive frames to tracks. The tracker is not safe for concurrent use.
+type Tracker struct {
+	config Config
+	tracks []*track
+	nextID int
+}
+
+// New creates a tracker with given config, zero values fall back to the defaults.
+func New(config Config) *Tracker {
+	defaults := DefaultConfig()
+	if config.HighThreshold == 0 {
+		config.HighThreshold = defaults.HighThreshold
+	}
+	if config.LowThreshold == 0 {
+		config.LowThreshold = defaults.LowThreshold
+	}
+	if config.NewTrackThreshold == 0 {
+		config.NewTrackThreshold = defaults.NewTrackThreshold
+	}
+	if config.MatchIoU == 0 {
+		config.MatchIoU = defaults.MatchIoU
+	}
+	if config.LowMatchIoU == 0 {
+		config.LowMatchIoU = defaults.LowMatchIoU
+	}
+	if config.MinHits == 0 {
+		config.MinHits = defaults.MinHits
+	}
+	if config.MaxLost == 0 {
+		config.MaxLost = defaults.MaxLost
+	}
+	return &Tracker{
+		config: config,
+		nextID: 1,
+	}
+}
+
+// Update assigns the detections of the next frame to the tracks. It returns all tracks which
+// are still kept, including the lost ones, sorted on ID.
+func (t *Tracker) Update(detections []detect.ObjectDetection) []Track {
+	for _, tr := range t.tracks {
+		tr.filter.predict()
+		tr.Age++
+		tr.TimeSinceUpdate++
+	}
+
+	high, low := []detect.ObjectDetection{}, []detect.ObjectDetection{}
+	for _, detection := range detections {
+		switch {
+		case detection.Confidence >= t.config.HighThreshold:
+			high = append(high, detection)
+		case detection.Confidence >= t.config.LowThreshold:
+			low = append(low, detection)
+		}
+	}
+
+	tentative, established := []*track{}, []*track{}
+	for _, tr := range t.tracks {
+		if tr.State == Tentative {
+			tentative = append(tentative, tr)
+		} else {
+			established = append(established, tr)
+		}
+	}
+
+	// First association, confirmed and lost tracks with the high confidence detections
+	unmatchedTracks, unmatchedHigh := t.associate(established, high, t.config.MatchIoU)
+
+	// Second association, the remaining tracks which were still tracked with the low confidence detections
+	tracked := []*track{}
+	for _, tr := range unmatchedTracks {
+		if tr.State == Confirmed {
+			tracked = append(tracked, tr)
+		}
+	}
+	t.associate(tracked, low, t.config.LowMatchIoU)
+
+	// Third association, tentative tracks with the remaining high confidence detections
+	_, unmatchedHigh = t.associate(tentative, unmatchedHigh, t.config.MatchIoU)
+
+	kept := []*track{}
+	for _, tr := range t.tracks {
+		if tr.TimeSinceUpdate == 0 {
+			kept = append(kept, tr)
+			continue
+		}
+		// A tentative track is dropped as soon as it is missed
+		if tr.State == Tentative || tr.TimeSinceUpdate > t.config.MaxLost {
+			continue
+		}
+		tr.State = Lost
+		kept = append(kept, tr)
+	}
+
+	for _, detection := range unmatchedHigh {
+		if detection.Confidence < t.config.NewTrackThreshold {
+			continue
+		}
+		kept = append(kept, t.newTrack(detection))
+	}
+	t.tracks = kept
+	return t.Tracks()
+}
+
+// Tracks returns all tracks which are kept, sorted on ID.
+func (t *Tracker) Tracks() []Track {
+	tracks := make([]Track, 0, len(t.tracks))
+	for _, tr := range t.tracks {
+		tracks = append(tracks, tr.snapshot())
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks
+}
+
+// newTrack starts a new tentative track for given detection.
+func (t *Tracker) newTrack(detection detect.ObjectDetection) *track {
+	tr := &track{
+		Track: Track{
+			ID:        t.nextID,
+			State:     Tentative,
+			Detection: detection,
+			Hits:      1,
+		},
+		filter: newKalmanFilter(detection.BoundingBox),
+	}
+	if tr.Hits >= t.config.MinHits {
+		tr.State = Confirmed
+	}
+	t.nextID++
+	return tr
+}
+
+// associate matches the tracks with the detections and updates the matched tracks. The tracks
+// and detections which could not be matched are returned.
+func (t *Tracker) associate(tracks []*track, detections []detect.ObjectDetection, minIoU float32) ([]*track, []detect.ObjectDetection) {
+	if len(tracks) == 0 || len(detections) == 0 {
+		return tracks, detections
+	}
+
+	cost := make([][]float64, len(tracks))
+	for i, tr := range tracks {
+		cost[i] = make([]float64, len(detections))
+		predicted := tr.filter.box()
+		for j, detection := range detections {
+			cost[i][j] = 1
+			if !t.config.MatchAcrossClasses && detection.ClassID != tr.Detection.ClassID {
+				continue
+			}
+			cost[i][j] = 1 - float64(postprocess.IoU(predicted, detection.BoundingBox))
+		}
+	}
+
+	matchedDetections := make([]bool, len(detections))
+	unmatchedTracks := []*track{}
+	for i, j := range hungarian(cost) {
+		if j < 0 || 1-cost[i][j] < float64(minIoU) {
+			unmatchedTracks = append(unmatchedTracks, tracks[i])
+			continue
+		}
+		matchedDetections[j] = true
+		t.match(tracks[i], detections[j])
+	}
+
+	unmatchedDetections := []detect.ObjectDetection{}
+	for j, detection := range detections {
+		if !matchedDetections[j] {
+			unmatchedDetections = append(unmatchedDetections, detection)
+		}
+	}
+	return unmatchedTracks, unmatchedDetections
+}
+
+// match updates the track with its matched detection.
+func (t *Tracker) match(tr *track, detection detect.ObjectDetection) {
+	tr.filter.update(detection.BoundingBox)
+	tr.Detection = detection
+	tr.Hits++
+	tr.TimeSinceUpdate = 0
+	if tr.State == Lost || tr.Hits >= t.config.MinHits {
+		tr.State = Confirmed
+	}
+}
@@ -0,0 +1,225 @@
+package track
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/wimspaargaren/yolov5/detect"
+)
+
+type TrackTestSuite struct {
+	suite.Suite
+}
+
+func TestTrackTestSuite(t *testing.T) {
+	suite.Run(t, new(TrackTestSuite))
+}
+
+// object creates a detection of given class and confidence with a 40x60 box at given position.
+func object(classID int, confidence float32, x, y int) detect.ObjectDetection {
+	return detect.ObjectDetection{
+		ClassID:     classID,
+		ClassName:   []string{"person", "car"}[classID],
+		Confidence:  confidence,
+		BoundingBox: image.Rect(x, y, x+40, y+60),
+	}
+}
+
+// byID indexes the tracks on ID.
+func byID(tracks []Track) map[int]Track {
+	res := map[int]Track{}
+	for _, track := range tracks {
+		res[track.ID] = track
+	}
+	return res
+}
+
+func (s *TrackTestSuite) TestLinearTrajectory() {
+	tracker := New(DefaultConfig())
+
+	var tracks []Track
+	for frame := 0; frame < 20; frame++ {
+		tracks = tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10+5*frame, 100)})
+		s.Require().Len(tracks, 1)
+		s.Equal(1, tracks[0].ID)
+		s.Equal(frame+1, tracks[0].Hits)
+		s.Equal(frame, tracks[0].Age)
+		if frame < DefaultMinHits-1 {
+			s.Equal(Tentative, tracks[0].State)
+		} else {
+			s.Equal(Confirmed, tracks[0].State)
+		}
+	}
+	s.InDelta(5, tracks[0].VelocityX, 0.5)
+	s.InDelta(0, tracks[0].VelocityY, 0.5)
+	s.InDelta(10+5*19, tracks[0].BoundingBox.Min.X, 2)
+}
+
+func (s *TrackTestSuite) TestCrossingTrajectories() {
+	tracker := New(DefaultConfig())
+
+	var tracks []Track
+	for frame := 0; frame < 40; frame++ {
+		tracks = tracker.Update([]detect.ObjectDetection{
+			object(0, 0.9, 10+8*frame, 100),
+			object(0, 0.9, 330-8*frame, 130),
+		})
+	}
+	s.Require().Len(tracks, 2)
+	// The first object started on the left and moved right, which should still be track 1
+	ids := byID(tracks)
+	s.Greater(ids[1].VelocityX, 0.0)
+	s.Less(ids[2].VelocityX, 0.0)
+	s.InDelta(10+8*39, ids[1].Detection.BoundingBox.Min.X, 0)
+	s.InDelta(330-8*39, ids[2].Detection.BoundingBox.Min.X, 0)
+}
+
+func (s *TrackTestSuite) TestLowScoreAssociation() {
+	tracker := New(DefaultConfig())
+	for frame := 0; frame < 5; frame++ {
+		tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10+5*frame, 100)})
+	}
+
+	// The object gets occluded, its confidence drops below the high threshold
+	for frame := 5; frame < 10; frame++ {
+		tracks := tracker.Update([]detect.ObjectDetection{object(0, 0.3, 10+5*frame, 100)})
+		s.Require().Len(tracks, 1)
+		s.Equal(1, tracks[0].ID)
+		s.Equal(Confirmed, tracks[0].State)
+		s.Equal(0, tracks[0].TimeSinceUpdate)
+	}
+}
+
+func (s *TrackTestSuite) TestLowScoreDetectionsDoNotStartTracks() {
+	tracker := New(DefaultConfig())
+	tracks := tracker.Update([]detect.ObjectDetection{object(0, 0.3, 10, 100), object(0, 0.55, 200, 100)})
+	s.Empty(tracks)
+}
+
+func (s *TrackTestSuite) TestLostAndRecovered() {
+	tracker := New(DefaultConfig())
+	for frame := 0; frame < 10; frame++ {
+		tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10+5*frame, 100)})
+	}
+
+	for frame := 10; frame < 13; frame++ {
+		tracks := tracker.Update([]detect.ObjectDetection{})
+		s.Require().Len(tracks, 1)
+		s.Equal(Lost, tracks[0].State)
+		s.Equal(frame-9, tracks[0].TimeSinceUpdate)
+	}
+
+	// The object reappears where the motion model expects it
+	tracks := tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10+5*13, 100)})
+	s.Require().Len(tracks, 1)
+	s.Equal(1, tracks[0].ID)
+	s.Equal(Confirmed, tracks[0].State)
+}
+
+func (s *TrackTestSuite) TestLostTracksAreRemoved() {
+	config := DefaultConfig()
+	config.MaxLost = 2
+	tracker := New(config)
+	for frame := 0; frame < 5; frame++ {
+		tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10, 100)})
+	}
+	tracker.Update(nil)
+	tracker.Update(nil)
+	s.Len(tracker.Tracks(), 1)
+	s.Empty(tracker.Update(nil))
+
+	tracks := tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10, 100)})
+	s.Require().Len(tracks, 1)
+	s.Equal(2, tracks[0].ID)
+	s.Equal(Tentative, tracks[0].State)
+}
+
+func (s *TrackTestSuite) TestMissedTentativeTrackIsDropped() {
+	tracker := New(DefaultConfig())
+	tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10, 100)})
+	s.Empty(tracker.Update(nil))
+}
+
+func (s *TrackTestSuite) TestClassAwareMatching() {
+	tracker := New(DefaultConfig())
+	tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10, 100)})
+	tracks := tracker.Update([]detect.ObjectDetection{object(1, 0.9, 10, 100)})
+	s.Require().Len(tracks, 1)
+	s.Equal(2, tracks[0].ID)
+	s.Equal("car", tracks[0].Detection.ClassName)
+
+	config := DefaultConfig()
+	config.MatchAcrossClasses = true
+	tracker = New(config)
+	tracker.Update([]detect.ObjectDetection{object(0, 0.9, 10, 100)})
+	tracks = tracker.Update([]detect.ObjectDetection{object(1, 0.9, 10, 100)})
+	s.Require().Len(tracks, 1)
+	s.Equal(1, tracks[0].ID)
+}
+
+func (s *TrackTestSuite) TestStateString() {
+	s.Equal("tentative", Tentative.String())
+	s.Equal("confirmed", Confirmed.String())
+	s.Equal("lost", Lost.String())
+}
+
+func (s *TrackTestSuite) TestHungarian() {
+	tests := []struct {
+		Name     string
+		Cost     [][]float64
+		Expected []int
+	}{
+		{
+			Name:     "Empty",
+			Cost:     [][]float64{},
+			Expected: []int{},
+		},
+		{
+			Name: "Square",
+			Cost: [][]float64{
+				{4, 1, 3},
+				{2, 0, 5},
+				{3, 2, 2},
+			},
+			Expected: []int{1, 0, 2},
+		},
+		{
+			Name: "More columns than rows",
+			Cost: [][]float64{
+				{0.9, 0.1, 0.8},
+				{0.2, 0.3, 0.9},
+			},
+			Expected: []int{1, 0},
+		},
+		{
+			Name: "More rows than columns",
+			Cost: [][]float64{
+				{0.9, 0.1},
+				{0.2, 0.3},
+				{0.1, 0.9},
+			},
+			Expected: []int{1, -1, 0},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Expected, hungarian(test.Cost))
+		})
+	}
+}
+
+func (s *TrackTestSuite) TestMatrixInverse() {
+	m := matrix{rows: 2, cols: 2, data: []float64{4, 7, 2, 6}}
+	inv, err := m.inverse()
+	s.Require().NoError(err)
+	product := m.mul(inv)
+	for i, v := range identity(2).data {
+		s.InDelta(v, product.data[i], 1e-9)
+	}
+
+	_, err = matrix{rows: 2, cols: 2, data: []float64{1, 2, 2, 4}}.inverse()
+	s.Error(err)
+}
@@ -11,15 +11,23 @@
 package yolov5
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
+	"io"
 	"os"
-	"strings"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"gocv.io/x/gocv"
 
+	"github.com/wimspaargaren/yolov5/detect"
 	"github.com/wimspaargaren/yolov5/internal/ml"
+	"github.com/wimspaargaren/yolov5/internal/ml/gocvnet"
+	"github.com/wimspaargaren/yolov5/internal/postprocess"
+	"github.com/wimspaargaren/yolov5/labels"
 )
 
 // Default constants for initialising the yolov5 net.
@@ -29,6 +37,9 @@ const (
 
 	DefaultConfThreshold float32 = 0.5
 	DefaultNMSThreshold  float32 = 0.4
+
+	// Stride of the network, the input size should be a multiple of the stride
+	Stride = 32
 )
 
 // Config can be used to customise the settings of the neural network used for object detection.
@@ -45,15 +56,23 @@ type Config struct {
 	NetTargetType  gocv.NetTargetType
 	NetBackendType gocv.NetBackendType
 
+	// ModelID identifies the model in detection results, defaults to the file name of the model
+	ModelID string
+
 	// NewNet function can be used to inject a custom neural net
 	NewNet func(modelPath string) ml.NeuralNet
+	// NewNetFromBytes function can be used to inject a custom neural net, for models loaded from memory
+	NewNetFromBytes func(model []byte) (ml.NeuralNet, error)
 }
 
-// validate ensures that the basic fields of the config are set
+// validate ensures that the basic fields of the config are set, the other fields are checked by Validate
 func (c *Config) validate() {
 	if c.NewNet == nil {
 		c.NewNet = initializeNet
 	}
+	if c.NewNetFromBytes == nil {
+		c.NewNetFromBytes = initializeNetFromBytes
+	}
 	if c.InputWidth == 0 {
 		c.InputWidth = DefaultInputWidth
 	}
@@ -62,6 +81,40 @@ func (c *Config) validate() {
 	}
 }
 
+// Validate checks whether every setting of the config is within its valid range. The input
+// size should be a positive multiple of the stride of the network and the thresholds should be
+// within (0, 1]. The returned error is a *ConfigError.
+func (c *Config) Validate() error {
+	err := validateInputSize(c.InputWidth, c.InputHeight)
+	if err != nil {
+		return err
+	}
+	if c.ConfidenceThreshold <= 0 || c.ConfidenceThreshold > 1 {
+		return &ConfigError{Field: "ConfidenceThreshold", Value: c.ConfidenceThreshold, Reason: "should be within (0, 1]", Err: ErrInvalidConfig}
+	}
+	if c.NMSThreshold <= 0 || c.NMSThreshold > 1 {
+		return &ConfigError{Field: "NMSThreshold", Value: c.NMSThreshold, Reason: "should be within (0, 1]", Err: ErrInvalidConfig}
+	}
+	if c.NetTargetType < gocv.NetTargetCPU || c.NetTargetType > gocv.NetTargetCUDAFP16 {
+		return &ConfigError{Field: "NetTargetType", Value: c.NetTargetType, Reason: "is not a known target type", Err: ErrInvalidConfig}
+	}
+	if c.NetBackendType < gocv.NetBackendDefault || c.NetBackendType > gocv.NetBackendCUDA {
+		return &ConfigError{Field: "NetBackendType", Value: c.NetBackendType, Reason: "is not a known backend type", Err: ErrInvalidConfig}
+	}
+	return nil
+}
+
+// validateInputSize checks whether given input size is a positive multiple of the stride of the network.
+func validateInputSize(width, height int) error {
+	if width <= 0 || width%Stride != 0 {
+		return &ConfigError{Field: "InputWidth", Value: width, Reason: fmt.Sprintf("should be a positive multiple of %d", Stride), Err: ErrInvalidInputSize}
+	}
+	if height <= 0 || height%Stride != 0 {
+		return &ConfigError{Field: "InputHeight", Value: height, Reason: fmt.Sprintf("should be a positive multiple of %d", Stride), Err: ErrInvalidInputSize}
+	}
+	return nil
+}
+
 // DefaultConfig used to create a working yolov5 net out of the box.
 func DefaultConfig() Config {
 	return Config{
@@ -72,28 +125,35 @@ func DefaultConfig() Config {
 		NetTargetType:       gocv.NetTargetCPU,
 		NetBackendType:      gocv.NetBackendDefault,
 		NewNet:              initializeNet,
+		NewNetFromBytes:     initializeNetFromBytes,
 	}
 }
 
-// ObjectDetection represents information of an object detected by the neural net.
-type ObjectDetection struct {
-	ClassID     int
-	ClassName   string
-	BoundingBox image.Rectangle
-	Confidence  float32
-}
+// ObjectDetection represents information of an object detected by the neural net. It is
+// defined in package detect, so packages processing detections do not depend on gocv.
+type ObjectDetection = detect.ObjectDetection
 
 // Net the yolov5 net.
 type Net interface {
 	Close() error
 	GetDetections(gocv.Mat) ([]ObjectDetection, error)
 	GetDetectionsWithFilter(gocv.Mat, map[string]bool) ([]ObjectDetection, error)
+	Detect(gocv.Mat, ...DetectOption) ([]ObjectDetection, error)
+	DetectContext(context.Context, gocv.Mat, ...DetectOption) ([]ObjectDetection, error)
+	DetectResult(context.Context, gocv.Mat, ...DetectOption) (*DetectionResult, error)
+}
+
+// Reloader is implemented by nets which are able to replace their model without downtime.
+type Reloader interface {
+	Reload(modelPath, cocoNamePath string) error
 }
 
 // yoloNet the net implementation.
 type yoloNet struct {
-	net       ml.NeuralNet
-	cocoNames []string
+	mu     sync.RWMutex
+	model  *model
+	closed bool
+	config Config
 
 	DefaultInputWidth   int
 	DefaultInputHeight  int
@@ -101,6 +161,16 @@ type yoloNet struct {
 	DefaultNMSThreshold float32
 }
 
+// model a loaded neural net together with its coco names. The detections which are using
+// the model are tracked, so a replaced model can be closed once they are done.
+type model struct {
+	net       ml.NeuralNet
+	cocoNames []string
+	modelID   string
+
+	inFlight sync.WaitGroup
+}
+
 // NewNet creates new yolo net for given weight path, config and coconames list.
 func NewNet(modelPath, cocoNamePath string) (Net, error) {
 	return NewNetWithConfig(modelPath, cocoNamePath, DefaultConfig())
@@ -108,41 +178,112 @@ func NewNet(modelPath, cocoNamePath string) (Net, error) {
 
 // NewNetWithConfig creates new yolo net with given config.
 func NewNetWithConfig(modelPath, cocoNamePath string, config Config) (Net, error) {
-	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("path to net model not found")
+	config.validate()
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
-	cocoNames, err := getCocoNames(cocoNamePath)
+	m, err := loadModel(modelPath, cocoNamePath, config)
 	if err != nil {
 		return nil, err
 	}
 
-	config.validate()
+	return newYoloNet(m, config), nil
+}
 
-	net := config.NewNet(modelPath)
+// NewNetFromBytes creates new yolo net for given ONNX model and coco names, which makes it
+// possible to embed the model in the binary.
+func NewNetFromBytes(modelBytes []byte, cocoNames io.Reader, config Config) (Net, error) {
+	config.validate()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-	err = setNetTargetTypes(net, config)
+	m, err := loadModelFromBytes(modelBytes, cocoNames, config)
 	if err != nil {
 		return nil, err
 	}
 
+	return newYoloNet(m, config), nil
+}
+
+// newYoloNet creates the yolo net for given model and config.
+func newYoloNet(m *model, config Config) *yoloNet {
 	return &yoloNet{
-		net:                 net,
-		cocoNames:           cocoNames,
+		model:               m,
+		config:              config,
 		DefaultInputWidth:   config.InputWidth,
 		DefaultInputHeight:  config.InputHeight,
 		confidenceThreshold: config.ConfidenceThreshold,
 		DefaultNMSThreshold: config.NMSThreshold,
+	}
+}
+
+// loadModel creates the neural net and reads the coco names for given paths.
+func loadModel(modelPath, cocoNamePath string, config Config) (*model, error) {
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, modelPath)
+	}
+
+	cocoNames, err := labels.Load(cocoNamePath)
+	if err != nil {
+		return nil, err
+	}
+
+	net := config.NewNet(modelPath)
+
+	modelID := config.ModelID
+	if modelID == "" {
+		modelID = filepath.Base(modelPath)
+	}
+	return newModel(net, cocoNames, modelID, config)
+}
+
+// loadModelFromBytes creates the neural net and reads the coco names from memory.
+func loadModelFromBytes(modelBytes []byte, cocoNamesReader io.Reader, config Config) (*model, error) {
+	cocoNames, err := labels.Parse(cocoNamesReader)
+	if err != nil {
+		return nil, err
+	}
+
+	net, err := config.NewNetFromBytes(modelBytes)
+	if err != nil {
+		return nil, err
+	}
+	return newModel(net, cocoNames, config.ModelID, config)
+}
+
+// newModel configures the target types of given neural net and wraps it in a model.
+func newModel(net ml.NeuralNet, cocoNames []string, modelID string, config Config) (*model, error) {
+	err := setNetTargetTypes(net, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model{
+		net:       net,
+		cocoNames: cocoNames,
+		modelID:   modelID,
 	}, nil
 }
 
 // initializeNet default method for creating neural network, leveraging gocv.
 func initializeNet(modelPath string) ml.NeuralNet {
-	net := gocv.ReadNetFromONNX(modelPath)
-	return &net
+	return gocvnet.ReadONNX(modelPath)
 }
 
-func setNetTargetTypes(net ml.NeuralNet, config Config) error {
+// initializeNetFromBytes default method for creating neural network from memory, leveraging gocv.
+func initializeNetFromBytes(model []byte) (ml.NeuralNet, error) {
+	return gocvnet.ReadONNXBytes(model)
+}
+
+// setNetTargetTypes sets the backend and target types of the config, for nets which are backed by gocv.
+func setNetTargetTypes(neuralNet ml.NeuralNet, config Config) error {
+	net, ok := neuralNet.(gocvnet.Targetable)
+	if !ok {
+		return nil
+	}
+
 	err := net.SetPreferableBackend(config.NetBackendType)
 	if err != nil {
 		return err
@@ -155,147 +296,190 @@ func setNetTargetTypes(net ml.NeuralNet, config Config) error {
 	return nil
 }
 
-// Close closes the net.
-func (y *yoloNet) Close() error {
-	return y.net.Close()
+// Close waits for the detections in flight and closes the net.
+func (y *yoloNet) Close() (err error) {
+	defer recoverPanic("close", &err)
+	y.mu.Lock()
+	defer y.mu.Unlock()
+	if y.closed {
+		return nil
+	}
+	y.closed = true
+	y.model.inFlight.Wait()
+	return y.model.net.Close()
+}
+
+// acquireModel returns the current model, the caller should call inFlight.Done once it is done using it.
+func (y *yoloNet) acquireModel() *model {
+	y.mu.RLock()
+	defer y.mu.RUnlock()
+	y.model.inFlight.Add(1)
+	return y.model
 }
 
 // GetDetections retrieve predicted detections from given matrix.
 func (y *yoloNet) GetDetections(frame gocv.Mat) ([]ObjectDetection, error) {
-	return y.GetDetectionsWithFilter(frame, make(map[string]bool))
+	return y.Detect(frame)
 }
 
 // GetDetectionsWithFilter allows you to detect objects, but filter out a given list of coco name ids.
 func (y *yoloNet) GetDetectionsWithFilter(frame gocv.Mat, classIDsFilter map[string]bool) ([]ObjectDetection, error) {
-	blob := gocv.BlobFromImage(frame, 1.0/255.0, image.Pt(y.DefaultInputWidth, y.DefaultInputHeight), gocv.NewScalar(0, 0, 0, 0), true, false)
-	// nolint: errcheck
-	defer blob.Close()
-	y.net.SetInput(blob, "")
-	layerIDs := y.net.GetUnconnectedOutLayers()
-	fl := []string{}
+	return y.Detect(frame, WithClassFilter(classIDsFilter))
+}
 
-	for _, id := range layerIDs {
-		layer := y.net.GetLayer(id)
-		fl = append(fl, layer.GetName())
-	}
-	outputs := y.net.ForwardLayers(fl)
-	for i := 0; i < len(outputs); i++ {
-		// nolint: errcheck
-		defer outputs[i].Close()
-	}
+// Detect retrieves predicted detections from given matrix, the given options override
+// the settings of the net for this call only.
+func (y *yoloNet) Detect(frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	return y.DetectContext(context.Background(), frame, opts...)
+}
 
-	detections, err := y.processOutputs(frame, outputs, classIDsFilter)
+// DetectContext retrieves predicted detections from given matrix. The context is checked for
+// cancellation between the preprocess, forward and decode stages. Note that a forward pass
+// which has already started can not be interrupted.
+func (y *yoloNet) DetectContext(ctx context.Context, frame gocv.Mat, opts ...DetectOption) ([]ObjectDetection, error) {
+	result, err := y.DetectResult(ctx, frame, opts...)
 	if err != nil {
 		return nil, err
 	}
-
-	return detections, nil
+	return result.Detections, nil
 }
 
-// processOutputs process detected rows in the outputs.
-func (y *yoloNet) processOutputs(frame gocv.Mat, outputs []gocv.Mat, filter map[string]bool) ([]ObjectDetection, error) {
-	// FIXME add filter functionality
-	_ = filter
+// DetectResult retrieves predicted detections from given matrix, together with the metadata
+// of the frame and the duration of every stage of the detection. A panic during the detection,
+// for example caused by a malformed frame, is returned as *PanicError.
+func (y *yoloNet) DetectResult(ctx context.Context, frame gocv.Mat, opts ...DetectOption) (result *DetectionResult, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("detection cancelled before preprocessing: %w", err)
+	}
+	m := y.acquireModel()
+	defer m.inFlight.Done()
+	defer recoverPanic("detection", &err)
+
+	return y.detect(ctx, m, frame, y.detectOptions(opts))
+}
 
-	detections := []ObjectDetection{}
-	bboxes := []image.Rectangle{}
-	confidences := []float32{}
-	data, err := outputs[0].DataPtrFloat32()
+// detect runs the detection on given frame using given model.
+func (y *yoloNet) detect(ctx context.Context, m *model, frame gocv.Mat, o DetectOptions) (*DetectionResult, error) {
+	if err := validateInputSize(o.InputWidth, o.InputHeight); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	frame, release, err := normaliseFrame(frame)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	rows := 25200
-	stepSize := 85
+	result := &DetectionResult{
+		Detections: []ObjectDetection{},
+		FrameSize:  image.Pt(frame.Cols(), frame.Rows()),
+		Model:      m.modelID,
+	}
 
-	for i := 0; i < rows; i++ {
-		confidence := data[4+stepSize*i]
-		if confidence >= .4 {
-			startIndex := 5 + stepSize*i
-			endIndex := stepSize * (i + 1)
+	if !o.ROI.Empty() {
+		roi := o.ROI.Intersect(image.Rect(0, 0, frame.Cols(), frame.Rows()))
+		if roi.Empty() {
+			return result, nil
+		}
+		region := frame.Region(roi)
+		// nolint: errcheck
+		defer region.Close()
+		frame = region
+		result.Transform.Offset = roi.Min
+	}
+	result.Transform.ScaleX = float32(frame.Cols()) / float32(o.InputWidth)
+	result.Transform.ScaleY = float32(frame.Rows()) / float32(o.InputHeight)
 
-			scores := data[startIndex:endIndex]
+	blob := gocv.BlobFromImage(frame, 1.0/255.0, image.Pt(o.InputWidth, o.InputHeight), gocv.NewScalar(0, 0, 0, 0), true, false)
+	// nolint: errcheck
+	defer blob.Close()
+	result.Preprocess = time.Since(start)
 
-			classID := getClassID(scores)
-			confidences = append(confidences, confidence)
-			boundingBox := calculateBoundingBox(frame, data[0+stepSize*i:4+stepSize*i])
-			bboxes = append(bboxes, boundingBox)
-			detections = append(detections, ObjectDetection{
-				ClassID:     classID,
-				ClassName:   y.cocoNames[classID],
-				BoundingBox: boundingBox,
-				Confidence:  confidence,
-			})
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("detection cancelled before forward pass: %w", err)
+	}
+	start = time.Now()
+	outputs, err := m.net.Forward(&gocvnet.MatTensor{Mat: blob})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(outputs); i++ {
+		if closer, ok := outputs[i].(io.Closer); ok {
+			// nolint: errcheck
+			defer closer.Close()
 		}
 	}
-
-	if len(bboxes) == 0 {
-		return detections, nil
+	result.Forward = time.Since(start)
+	if profiler, ok := m.net.(ml.Profiler); ok {
+		result.Forward = profiler.LastForwardDuration()
 	}
 
-	indices := gocv.NMSBoxes(bboxes, confidences, y.confidenceThreshold, y.DefaultNMSThreshold)
-	result := []ObjectDetection{}
-	for i, indice := range indices {
-		// If we encounter value 0 skip the detection
-		// except for the first indice
-		if i != 0 && indice == 0 {
-			continue
-		}
-		result = append(result, detections[indice])
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("detection cancelled before decoding: %w", err)
+	}
+	start = time.Now()
+	detections, err := m.processOutputs(outputs, result.Transform, o)
+	if err != nil {
+		return nil, err
 	}
+	result.Detections = detections
+	result.Postprocess = time.Since(start)
 	return result, nil
 }
 
-func (y *yoloNet) isFiltered(classID int, classIDs map[string]bool) bool {
-	if classIDs == nil {
-		return false
+// processOutputs process detected rows in the outputs and maps them onto the frame using given transform.
+func (m *model) processOutputs(outputs []ml.Tensor, transform Transform, opts DetectOptions) ([]ObjectDetection, error) {
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("net did not produce any outputs")
 	}
-	return classIDs[y.cocoNames[classID]]
-}
 
-// calculateBoundingBox calculate the bounding box of the detected object.
-func calculateBoundingBox(frame gocv.Mat, row []float32) image.Rectangle {
-	if len(row) < 4 {
-		return image.Rect(0, 0, 0, 0)
+	// The output is shaped as [batch, rows, 5 + classes]
+	shape := outputs[0].Shape()
+	if len(shape) == 3 && shape[2]-5 > len(m.cocoNames) {
+		return nil, fmt.Errorf("%w: net output of shape %v predicts %d classes, but only %d coco names are known", ErrLabelsMismatch, shape, shape[2]-5, len(m.cocoNames))
 	}
-	xFactor := float32(frame.Cols()) / float32(640)
-	yFactor := float32(frame.Rows()) / float32(640)
 
-	x, y, w, h := row[0], row[1], row[2], row[3]
-	left := int((x - 0.5*w) * xFactor)
-	top := int((y - 0.5*h) * yFactor)
-	width := int(w * xFactor)
-	height := int(h * yFactor)
-
-	return image.Rect(left, top, left+width, top+height)
-}
+	decoded, err := postprocess.Decode(outputs[0], postprocess.Params{
+		ScaleX:              transform.ScaleX,
+		ScaleY:              transform.ScaleY,
+		ConfidenceThreshold: opts.ConfidenceThreshold,
+		NMSThreshold:        opts.NMSThreshold,
+		MaxDetections:       opts.MaxDetections,
+		Filter: func(classID int) bool {
+			return m.isFiltered(classID, opts.ClassIDsFilter)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func getClassID(x []float32) int {
-	res := 0
-	max := float32(0)
-	for i, y := range x {
-		if y > max {
-			res = i
-			max = y
+	detections := make([]ObjectDetection, 0, len(decoded))
+	for _, detection := range decoded {
+		if detection.ClassID < 0 || detection.ClassID >= len(m.cocoNames) {
+			return nil, fmt.Errorf("%w: net output of shape %v contains class index %d, but only %d coco names are known", ErrLabelsMismatch, shape, detection.ClassID, len(m.cocoNames))
 		}
+		detections = append(detections, ObjectDetection{
+			ClassID:     detection.ClassID,
+			ClassName:   m.cocoNames[detection.ClassID],
+			BoundingBox: detection.BoundingBox.Add(transform.Offset),
+			Confidence:  detection.Confidence,
+		})
 	}
-	return res
+	return detections, nil
 }
 
-// getCocoNames read coconames from given path.
-func getCocoNames(path string) ([]string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+func (m *model) isFiltered(classID int, classIDs map[string]bool) bool {
+	if classIDs == nil || classID < 0 || classID >= len(m.cocoNames) {
+		return false
 	}
-	return strings.Split(string(content), "\n"), nil
+	return classIDs[m.cocoNames[classID]]
 }
 
 // DrawDetections draws a given list of object detections on a gocv Matrix.
 func DrawDetections(frame *gocv.Mat, detections []ObjectDetection) {
 	for i := 0; i < len(detections); i++ {
 		detection := detections[i]
-		text := fmt.Sprintf("%s:%.2f%%", detection.ClassName, detection.Confidence*100)
+		text := detectionLabel(detection)
 
 		// Create bounding box of object
 		blue := color.RGBA{0, 0, 255, 0}
@@ -313,4 +497,12 @@ func DrawDetections(frame *gocv.Mat, detections []ObjectDetection) {
 		white := color.RGBA{255, 255, 255, 0}
 		gocv.PutText(frame, text, pt, gocv.FontHersheySimplex, 0.5, white, 1)
 	}
-}
\ No newline at end of file
+}
+// detectionLabel returns the text drawn above a detection, the sub-label is shown next to the class.
+func detectionLabel(detection ObjectDetection) string {
+	text := fmt.Sprintf("%s:%.2f%%", detection.ClassName, detection.Confidence*100)
+	if detection.SubLabel != "" {
+		text += fmt.Sprintf(" / %s:%.2f%%", detection.SubLabel, detection.SubLabelConfidence*100)
+	}
+	return text
+}
@@ -2,6 +2,8 @@
 package yolov5
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image"
 	"os"
@@ -14,9 +16,17 @@ import (
 	"gocv.io/x/gocv"
 
 	"github.com/wimspaargaren/yolov5/internal/ml"
+	gocvnetmocks "github.com/wimspaargaren/yolov5/internal/ml/gocvnet/mocks"
 	"github.com/wimspaargaren/yolov5/internal/ml/mocks"
+	"github.com/wimspaargaren/yolov5/internal/ml/refnet"
 )
 
+// targetableNeuralNetMock mocks a neural net backed by gocv.
+type targetableNeuralNetMock struct {
+	*mocks.MockNeuralNet
+	*gocvnetmocks.MockTargetable
+}
+
 type YoloTestSuite struct {
 	suite.Suite
 }
@@ -34,8 +44,9 @@ func (s *YoloTestSuite) TestNewDefaultNetCorrectCreation() {
 	s.Require().NoError(err)
 	yoloNet := net.(*yoloNet)
 
-	s.NotNil(yoloNet.net)
-	s.Equal(81, len(yoloNet.cocoNames))
+	s.NotNil(yoloNet.model.net)
+	s.Equal(80, len(yoloNet.model.cocoNames))
+	s.Equal("yolov5s.onnx", yoloNet.model.modelID)
 	s.Equal(DefaultInputWidth, yoloNet.DefaultInputWidth)
 	s.Equal(DefaultInputHeight, yoloNet.DefaultInputHeight)
 	s.Equal(DefaultConfThreshold, yoloNet.confidenceThreshold)
@@ -45,34 +56,37 @@ func (s *YoloTestSuite) TestNewDefaultNetCorrectCreation() {
 }
 
 func (s *YoloTestSuite) TestNewCustomConfig_MissingNewNetFunc_CorrectCreation() {
-	net, err := NewNetWithConfig("data/yolov5/yolov5s.onnx", "data/yolov5/coco.names", Config{})
+	net, err := NewNetWithConfig("data/yolov5/yolov5s.onnx", "data/yolov5/coco.names", Config{
+		ConfidenceThreshold: 0.25,
+		NMSThreshold:        0.45,
+	})
 	s.Require().NoError(err)
 	yoloNet := net.(*yoloNet)
 
-	s.NotNil(yoloNet.net)
-	s.Equal(81, len(yoloNet.cocoNames))
+	s.NotNil(yoloNet.model.net)
+	s.Equal(80, len(yoloNet.model.cocoNames))
 	s.Equal(DefaultInputWidth, yoloNet.DefaultInputWidth)
 	s.Equal(DefaultInputHeight, yoloNet.DefaultInputHeight)
-	s.Equal(float32(0), yoloNet.confidenceThreshold)
-	s.Equal(float32(0), yoloNet.DefaultNMSThreshold)
+	s.Equal(float32(0.25), yoloNet.confidenceThreshold)
+	s.Equal(float32(0.45), yoloNet.DefaultNMSThreshold)
 
 	s.NoError(yoloNet.Close())
 }
 
 func (s *YoloTestSuite) TestUnableTocCreateNewNet() {
+	errBroken := fmt.Errorf("very broken")
 	tests := []struct {
 		Name               string
 		ModelPath          string
 		CocoNamePath       string
-		Config             Config
 		Error              error
-		SetupNeuralNetMock func() *mocks.MockNeuralNet
+		SetupNeuralNetMock func() *targetableNeuralNetMock
 	}{
 		{
 			Name:         "Non existent weights path",
 			ModelPath:    "data/yolov5/notexistent",
 			CocoNamePath: "data/yolov5/coco.names",
-			Error:        fmt.Errorf("path to net model not found"),
+			Error:        ErrModelNotFound,
 		},
 		{
 			Name:         "Non existent coco names path",
@@ -83,110 +97,207 @@ func (s *YoloTestSuite) TestUnableTocCreateNewNet() {
 			Name:         "Unable to set preferable backend",
 			ModelPath:    "data/yolov5/yolov5s.onnx",
 			CocoNamePath: "data/yolov5/coco.names",
-			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+			SetupNeuralNetMock: func() *targetableNeuralNetMock {
 				controller := gomock.NewController(s.T())
-				neuralNetMock := mocks.NewMockNeuralNet(controller)
-				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(fmt.Errorf("very broken")).Times(1)
-				return neuralNetMock
+				targetableMock := gocvnetmocks.NewMockTargetable(controller)
+				targetableMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(errBroken).Times(1)
+				return &targetableNeuralNetMock{mocks.NewMockNeuralNet(controller), targetableMock}
 			},
-			Error: fmt.Errorf("very broken"),
+			Error: errBroken,
 		},
 		{
 			Name:         "Unable to set preferable target type",
 			ModelPath:    "data/yolov5/yolov5s.onnx",
 			CocoNamePath: "data/yolov5/coco.names",
-			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
+			SetupNeuralNetMock: func() *targetableNeuralNetMock {
 				controller := gomock.NewController(s.T())
-				neuralNetMock := mocks.NewMockNeuralNet(controller)
-				neuralNetMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
-				neuralNetMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(fmt.Errorf("very broken")).Times(1)
-				return neuralNetMock
+				targetableMock := gocvnetmocks.NewMockTargetable(controller)
+				targetableMock.EXPECT().SetPreferableBackend(gomock.Any()).Return(nil).Times(1)
+				targetableMock.EXPECT().SetPreferableTarget(gomock.Any()).Return(errBroken).Times(1)
+				return &targetableNeuralNetMock{mocks.NewMockNeuralNet(controller), targetableMock}
 			},
-			Error: fmt.Errorf("very broken"),
+			Error: errBroken,
 		},
 	}
 
 	for _, test := range tests {
 		s.Run(test.Name, func() {
-			test.Config.NewNet = func(string) ml.NeuralNet {
+			config := DefaultConfig()
+			config.NewNet = func(string) ml.NeuralNet {
 				return test.SetupNeuralNetMock()
 			}
-			_, err := NewNetWithConfig(test.ModelPath, test.CocoNamePath, test.Config)
+			_, err := NewNetWithConfig(test.ModelPath, test.CocoNamePath, config)
 			s.Error(err)
 			if test.Error != nil {
-				s.Equal(test.Error, err)
+				s.ErrorIs(err, test.Error)
 			}
 		})
 	}
 }
 
-func (s *YoloTestSuite) TestClassIDAndConfidence() {
+func (s *YoloTestSuite) TestValidateConfig() {
 	tests := []struct {
-		Name              string
-		Input             []float32
-		ExpectedIndex     int
-		ExpetedConfidence float32
+		Name   string
+		Modify func(*Config)
+		Error  error
 	}{
 		{
-			Name:              "no inputs",
-			ExpectedIndex:     0,
-			ExpetedConfidence: 0,
+			Name:   "Default config",
+			Modify: func(*Config) {},
 		},
 		{
-			Name:              "single inputs",
-			Input:             []float32{99.9},
-			ExpectedIndex:     0,
-			ExpetedConfidence: 99.9,
+			Name:   "Negative input width",
+			Modify: func(c *Config) { c.InputWidth = -640 },
+			Error:  ErrInvalidInputSize,
 		},
 		{
-			Name:              "single inputs",
-			Input:             []float32{70.0, 99.9},
-			ExpectedIndex:     1,
-			ExpetedConfidence: 99.9,
+			Name:   "Input height not a multiple of the stride",
+			Modify: func(c *Config) { c.InputHeight = 300 },
+			Error:  ErrInvalidInputSize,
 		},
 		{
-			Name:              "single inputs",
-			Input:             []float32{99.9, 70.0},
-			ExpectedIndex:     0,
-			ExpetedConfidence: 99.9,
+			Name:   "Zero confidence threshold",
+			Modify: func(c *Config) { c.ConfidenceThreshold = 0 },
+			Error:  ErrInvalidConfig,
+		},
+		{
+			Name:   "Confidence threshold above one",
+			Modify: func(c *Config) { c.ConfidenceThreshold = 1.5 },
+			Error:  ErrInvalidConfig,
+		},
+		{
+			Name:   "Zero NMS threshold",
+			Modify: func(c *Config) { c.NMSThreshold = 0 },
+			Error:  ErrInvalidConfig,
+		},
+		{
+			Name:   "Unknown target type",
+			Modify: func(c *Config) { c.NetTargetType = 42 },
+			Error:  ErrInvalidConfig,
+		},
+		{
+			Name:   "Unknown backend type",
+			Modify: func(c *Config) { c.NetBackendType = -1 },
+			Error:  ErrInvalidConfig,
 		},
 	}
 
 	for _, test := range tests {
 		s.Run(test.Name, func() {
-			index := getClassID(test.Input)
-			s.Equal(test.ExpectedIndex, index)
+			config := DefaultConfig()
+			test.Modify(&config)
+			err := config.Validate()
+			if test.Error == nil {
+				s.NoError(err)
+				return
+			}
+			s.ErrorIs(err, test.Error)
+			var configErr *ConfigError
+			s.ErrorAs(err, &configErr)
 		})
 	}
 }
 
-func (s *YoloTestSuite) TestCalculateBoundingBox() {
-	tests := []struct {
-		Name         string
-		InputFrame   gocv.Mat
-		InputRow     []float32
-		ExpectedRect image.Rectangle
-	}{
-		// FIXME
-		// {
-		// 	Name:         "normal bounding box calculation",
-		// 	InputFrame:   gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-		// 	InputRow:     []float32{1, 1, 1, 1},
-		// 	ExpectedRect: image.Rect(1, 1, 3, 3),
-		// },
-		{
-			Name:         "unexpected row",
-			InputFrame:   gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-			InputRow:     []float32{1, 1, 1},
-			ExpectedRect: image.Rect(0, 0, 0, 0),
+func (s *YoloTestSuite) TestNewNetWithConfigZeroThresholds() {
+	_, err := NewNetWithConfig("data/yolov5/yolov5s.onnx", "data/yolov5/coco.names", Config{
+		NewNet: func(string) ml.NeuralNet {
+			s.Fail("net should not be initialised")
+			return nil
 		},
+	})
+	s.ErrorIs(err, ErrInvalidConfig)
+}
+
+func (s *YoloTestSuite) TestClosePanicRecovered() {
+	controller := gomock.NewController(s.T())
+	neuralNet := mocks.NewMockNeuralNet(controller)
+	neuralNet.EXPECT().Close().Do(func() {
+		panic("malformed net")
+	}).Times(1)
+	y := &yoloNet{model: &model{net: neuralNet}}
+
+	err := y.Close()
+	var panicErr *PanicError
+	s.Require().ErrorAs(err, &panicErr)
+	s.Equal("close", panicErr.Op)
+	s.Equal("malformed net", panicErr.Value)
+	s.NotEmpty(panicErr.Stack)
+}
+
+func (s *YoloTestSuite) TestRecoverPanicUnwrapsErrors() {
+	errBroken := errors.New("very broken")
+	err := func() (err error) {
+		defer recoverPanic("detection", &err)
+		panic(errBroken)
+	}()
+	s.ErrorIs(err, errBroken)
+	s.EqualError(err, "recovered from panic during detection: very broken")
+}
+
+func (s *YoloTestSuite) TestIsFilteredOutOfRange() {
+	m := &model{cocoNames: []string{"laptop"}}
+	s.False(m.isFiltered(3, map[string]bool{"laptop": true}))
+}
+
+func (s *YoloTestSuite) TestDetectInvalidInputSize() {
+	y := &yoloNet{
+		model:               &model{},
+		confidenceThreshold: DefaultConfThreshold,
+		DefaultNMSThreshold: DefaultNMSThreshold,
+		DefaultInputWidth:   DefaultInputWidth,
+		DefaultInputHeight:  DefaultInputHeight,
 	}
-	for _, test := range tests {
-		s.Run(test.Name, func() {
-			rect := calculateBoundingBox(test.InputFrame, test.InputRow)
-			s.Equal(test.ExpectedRect, rect)
-		})
+	_, err := y.Detect(gocv.Mat{}, WithInputSize(0, 640))
+	s.ErrorIs(err, ErrInvalidInputSize)
+}
+
+func (s *YoloTestSuite) TestDetectContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	y := &yoloNet{
+		model: &model{cocoNames: []string{"laptop", "coffee"}},
+	}
+	detections, err := y.DetectContext(ctx, gocv.Mat{})
+	s.Nil(detections)
+	s.True(errors.Is(err, context.Canceled))
+
+	result, err := y.DetectResult(ctx, gocv.Mat{})
+	s.Nil(result)
+	s.True(errors.Is(err, context.Canceled))
+}
+
+func (s *YoloTestSuite) TestDetectOptions() {
+	y := &yoloNet{
+		DefaultInputWidth:   DefaultInputWidth,
+		DefaultInputHeight:  DefaultInputHeight,
+		confidenceThreshold: DefaultConfThreshold,
+		DefaultNMSThreshold: DefaultNMSThreshold,
 	}
+
+	s.Equal(DetectOptions{
+		ConfidenceThreshold: DefaultConfThreshold,
+		NMSThreshold:        DefaultNMSThreshold,
+		InputWidth:          DefaultInputWidth,
+		InputHeight:         DefaultInputHeight,
+	}, y.detectOptions(nil))
+
+	s.Equal(DetectOptions{
+		ConfidenceThreshold: 0.8,
+		NMSThreshold:        0.3,
+		ClassIDsFilter:      map[string]bool{"coffee": true},
+		MaxDetections:       5,
+		ROI:                 image.Rect(10, 10, 100, 100),
+		InputWidth:          320,
+		InputHeight:         320,
+	}, y.detectOptions([]DetectOption{
+		WithConfidenceThreshold(0.8),
+		WithNMSThreshold(0.3),
+		WithClassFilter(map[string]bool{"coffee": true}),
+		WithMaxDetections(5),
+		WithROI(image.Rect(10, 10, 100, 100)),
+		WithInputSize(320, 320),
+	}))
 }
 
 func (s *YoloTestSuite) TestIsFiltered() {
@@ -215,215 +326,124 @@ func (s *YoloTestSuite) TestIsFiltered() {
 	}
 	for _, test := range tests {
 		s.Run(test.Name, func() {
-			y := &yoloNet{
+			m := &model{
 				cocoNames: []string{"laptop", "coffee"},
 			}
-			s.Equal(test.Expected, y.isFiltered(test.ClassID, test.ClassIDs))
+			s.Equal(test.Expected, m.isFiltered(test.ClassID, test.ClassIDs))
 		})
 	}
 }
 
-// FIXME
-// func (s *YoloTestSuite) TestProcessOutputs() {
-// 	tests := []struct {
-// 		Name                      string
-// 		InputFrame                gocv.Mat
-// 		InputOutputs              []gocv.Mat
-// 		InputFilter               map[string]bool
-// 		InputConfidenceThreshHold float32
-// 		Result                    []ObjectDetection
-// 		ExpectError               bool
-// 	}{
-// 		{
-// 			Name:       "Two rows containing two predictions",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			InputOutputs: func() []gocv.Mat {
-// 				laptopDetection := laptopDetection()
-// 				coffeeDetection := coffeeDetection()
-
-// 				return []gocv.Mat{laptopDetection, coffeeDetection}
-// 			}(),
-// 			InputFilter: map[string]bool{},
-// 			Result: []ObjectDetection{
-// 				{
-// 					ClassID:     0,
-// 					Confidence:  9,
-// 					ClassName:   "laptop",
-// 					BoundingBox: image.Rect(1, 1, 3, 3),
-// 				},
-// 				{
-// 					ClassID:     1,
-// 					Confidence:  9,
-// 					ClassName:   "coffee",
-// 					BoundingBox: image.Rect(-1, 1, 1, 3),
-// 				},
-// 			},
-// 		},
-// 		{
-// 			Name:       "Incorrect input layer provided",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			InputOutputs: func() []gocv.Mat {
-// 				return []gocv.Mat{gocv.NewMatWithSize(1, 10, gocv.MatTypeCV16S)}
-// 			}(),
-// 			ExpectError: true,
-// 		},
-// 		{
-// 			Name:       "Result was filtered",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			InputOutputs: func() []gocv.Mat {
-// 				coffeeDetection := coffeeDetection()
-
-// 				return []gocv.Mat{coffeeDetection}
-// 			}(),
-// 			InputFilter: map[string]bool{"coffee": true},
-// 			Result:      []ObjectDetection{},
-// 		},
-// 		{
-// 			Name:       "Confidence not high enough",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			InputOutputs: func() []gocv.Mat {
-// 				coffeeDetection := coffeeDetection()
-
-// 				return []gocv.Mat{coffeeDetection}
-// 			}(),
-// 			InputConfidenceThreshHold: 999,
-// 			InputFilter:               map[string]bool{"coffee": true},
-// 			Result:                    []ObjectDetection{},
-// 		},
-// 		{
-// 			Name:       "Filter overlapping frame",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			InputOutputs: func() []gocv.Mat {
-// 				coffeeDetection1 := coffeeDetection()
-// 				coffeeDetection2 := coffeeDetection()
-// 				coffeeDetection2.SetFloatAt(0, 6, 10)
-// 				return []gocv.Mat{coffeeDetection1, coffeeDetection2}
-// 			}(),
-// 			InputFilter: map[string]bool{},
-// 			Result: []ObjectDetection{
-// 				{
-// 					ClassID:     1,
-// 					Confidence:  10,
-// 					ClassName:   "coffee",
-// 					BoundingBox: image.Rect(-1, 1, 1, 3),
-// 				},
-// 			},
-// 		},
-// 	}
-// 	for _, test := range tests {
-// 		s.Run(test.Name, func() {
-// 			y := &yoloNet{
-// 				cocoNames:           []string{"laptop", "coffee"},
-// 				confidenceThreshold: test.InputConfidenceThreshHold,
-// 			}
-// 			detections, err := y.processOutputs(test.InputFrame, test.InputOutputs, test.InputFilter)
-// 			if test.ExpectError {
-// 				s.Error(err)
-// 			} else {
-// 				s.Require().NoError(err)
-// 			}
-// 			s.Equal(test.Result, detections)
-// 		})
-// 	}
-// }
-
-// FIXME
-// func (s *YoloTestSuite) TestGetDetections() {
-// 	tests := []struct {
-// 		Name                      string
-// 		InputFrame                gocv.Mat
-// 		InputConfidenceThreshHold float32
-// 		Result                    []ObjectDetection
-// 		ExpectError               bool
-// 		SetupNeuralNetMock        func() *mocks.MockNeuralNet
-// 		Panics                    bool
-// 	}{
-// 		{
-// 			Name:       "Get successful detection",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
-// 				controller := gomock.NewController(s.T())
-// 				neuralNetMock := mocks.NewMockNeuralNet(controller)
-// 				neuralNetMock.EXPECT().SetInput(gomock.Any(), "data").Times(1)
-
-// 				neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).Return(func() []gocv.Mat {
-// 					laptopDetection := laptopDetection()
-// 					coffeeDetection := coffeeDetection()
-
-// 					return []gocv.Mat{laptopDetection, coffeeDetection}
-// 				}()).Times(1)
-// 				return neuralNetMock
-// 			},
-// 			Result: []ObjectDetection{
-// 				{
-// 					ClassID:     0,
-// 					Confidence:  9,
-// 					ClassName:   "laptop",
-// 					BoundingBox: image.Rect(1, 1, 3, 3),
-// 				},
-// 				{
-// 					ClassID:     1,
-// 					Confidence:  9,
-// 					ClassName:   "coffee",
-// 					BoundingBox: image.Rect(-1, 1, 1, 3),
-// 				},
-// 			},
-// 		},
-// 		{
-// 			Name:       "Incorrect input layer provided",
-// 			InputFrame: gocv.NewMatWithSize(2, 2, gocv.MatTypeCV32F),
-// 			SetupNeuralNetMock: func() *mocks.MockNeuralNet {
-// 				controller := gomock.NewController(s.T())
-// 				neuralNetMock := mocks.NewMockNeuralNet(controller)
-// 				neuralNetMock.EXPECT().SetInput(gomock.Any(), "data").Times(1)
-// 				neuralNetMock.EXPECT().ForwardLayers(gomock.Any()).Return([]gocv.Mat{gocv.NewMatWithSize(1, 10, gocv.MatTypeCV16S)}).Times(1)
-// 				return neuralNetMock
-// 			},
-// 			ExpectError: true,
-// 		},
-// 	}
-// 	for _, test := range tests {
-// 		s.Run(test.Name, func() {
-// 			y := &yoloNet{
-// 				cocoNames:           []string{"laptop", "coffee"},
-// 				confidenceThreshold: test.InputConfidenceThreshHold,
-// 				net:                 test.SetupNeuralNetMock(),
-// 			}
-// 			if test.Panics {
-// 				s.Panics(func() { y.GetDetections(test.InputFrame) })
-// 			} else {
-// 				detections, err := y.GetDetections(test.InputFrame)
-// 				if test.ExpectError {
-// 					s.Error(err)
-// 				} else {
-// 					s.Require().NoError(err)
-// 				}
-// 				s.Equal(test.Result, detections)
-// 			}
-// 		})
-// 	}
-// }
-
-func laptopDetection() gocv.Mat {
-	laptopDetection := gocv.NewMatWithSize(1, 10, gocv.MatTypeCV32F)
-	laptopDetection.SetFloatAt(0, 0, 1)
-	laptopDetection.SetFloatAt(0, 1, 1)
-	laptopDetection.SetFloatAt(0, 2, 1)
-	laptopDetection.SetFloatAt(0, 3, 1)
-	// Index for laptop == 5
-	laptopDetection.SetFloatAt(0, 5, 9)
-	return laptopDetection
+func (s *YoloTestSuite) TestDetectionLabel() {
+	tests := []struct {
+		Name      string
+		Detection ObjectDetection
+		Expected  string
+	}{
+		{
+			Name:      "class only",
+			Detection: ObjectDetection{ClassName: "car", Confidence: 0.87},
+			Expected:  "car:87.00%",
+		},
+		{
+			Name:      "with sub-label",
+			Detection: ObjectDetection{ClassName: "car", Confidence: 0.87, SubLabel: "sedan", SubLabelConfidence: 0.92},
+			Expected:  "car:87.00% / sedan:92.00%",
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			s.Equal(test.Expected, detectionLabel(test.Detection))
+		})
+	}
 }
 
-func coffeeDetection() gocv.Mat {
-	coffeeDetection := gocv.NewMatWithSize(1, 10, gocv.MatTypeCV32F)
-	coffeeDetection.SetFloatAt(0, 1, 1)
-	coffeeDetection.SetFloatAt(0, 2, 1)
-	coffeeDetection.SetFloatAt(0, 3, 1)
-	coffeeDetection.SetFloatAt(0, 3, 1)
-	// Index for coffee == 6
-	coffeeDetection.SetFloatAt(0, 6, 9)
-	return coffeeDetection
+func (s *YoloTestSuite) TestProcessOutputs() {
+	laptop := refnet.Object{ClassID: 0, Confidence: 0.9, BoundingBox: image.Rect(0, 0, 10, 10)}
+	coffee := refnet.Object{ClassID: 1, Confidence: 0.8, BoundingBox: image.Rect(20, 20, 30, 40)}
+
+	tests := []struct {
+		Name        string
+		NumClasses  int
+		Objects     []refnet.Object
+		Transform   Transform
+		InputFilter map[string]bool
+		Result      []ObjectDetection
+		ExpectError bool
+	}{
+		{
+			Name:       "Two rows containing two predictions",
+			NumClasses: 2,
+			Objects:    []refnet.Object{laptop, coffee},
+			Transform:  Transform{ScaleX: 1, ScaleY: 1},
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  0.9,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(0, 0, 10, 10),
+				},
+				{
+					ClassID:     1,
+					Confidence:  0.8,
+					ClassName:   "coffee",
+					BoundingBox: image.Rect(20, 20, 30, 40),
+				},
+			},
+		},
+		{
+			Name:       "Mapped onto the frame",
+			NumClasses: 2,
+			Objects:    []refnet.Object{laptop},
+			Transform:  Transform{ScaleX: 2, ScaleY: 2, Offset: image.Pt(5, 5)},
+			Result: []ObjectDetection{
+				{
+					ClassID:     0,
+					Confidence:  0.9,
+					ClassName:   "laptop",
+					BoundingBox: image.Rect(5, 5, 25, 25),
+				},
+			},
+		},
+		{
+			Name:        "Result was filtered",
+			NumClasses:  2,
+			Objects:     []refnet.Object{coffee},
+			Transform:   Transform{ScaleX: 1, ScaleY: 1},
+			InputFilter: map[string]bool{"coffee": true},
+			Result:      []ObjectDetection{},
+		},
+		{
+			Name:        "More classes than coco names",
+			NumClasses:  3,
+			Objects:     []refnet.Object{coffee},
+			ExpectError: true,
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.Name, func() {
+			input, err := ml.NewTensor([]int{1, 3, 64, 64}, make([]float32, 3*64*64))
+			s.Require().NoError(err)
+			outputs, err := refnet.New(test.NumClasses, test.Objects...).Forward(input)
+			s.Require().NoError(err)
+
+			m := &model{
+				cocoNames: []string{"laptop", "coffee"},
+			}
+			detections, err := m.processOutputs(outputs, test.Transform, DetectOptions{
+				ConfidenceThreshold: DefaultConfThreshold,
+				NMSThreshold:        DefaultNMSThreshold,
+				ClassIDsFilter:      test.InputFilter,
+			})
+			if test.ExpectError {
+				s.ErrorIs(err, ErrLabelsMismatch)
+				s.ErrorContains(err, "[1 252 8]")
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(test.Result, detections)
+		})
+	}
 }
 
 func ExampleNewNet() {